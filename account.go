@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // AccountIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Account Identifier Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-account-identifier-format
@@ -34,6 +39,32 @@ func (id *accountIdentifier) Validate() error {
 	return nil
 }
 
+func (id *accountIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *accountIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *accountIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *accountIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "accountIdentifier")
+}
+
+func (id *accountIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatAccount)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*accountIdentifier)
+	return nil
+}
+
 // NewAccountIdentifier creates new instance of AccountIdentifier.
 // The argument "uri" is required. If it's empty, this function returns error.
 func NewAccountIdentifier(uri string) (AccountIdentifier, error) {
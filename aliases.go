@@ -1,6 +1,8 @@
 package secevsubid
 
 import (
+	"encoding/xml"
+	"log/slog"
 	"reflect"
 )
 
@@ -18,6 +20,7 @@ type AliasesIdentifier interface {
 	// AddIdentifier adds new SubjectIdentifier to internal list.
 	// In the following cases this method returns an error.
 	//   * The argument is in Aliases Identifier Format.
+	//   * The argument is Complex Subject.
 	//   * A SubjectIdentifier with the same content as the argument already exists.
 	AddIdentifier(identifier SubjectIdentifier) error
 	// Validate values held and returns an error if there is a problem.
@@ -47,6 +50,32 @@ func (id *aliasesIdentifier) Validate() error {
 	return nil
 }
 
+func (id *aliasesIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *aliasesIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *aliasesIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *aliasesIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "aliasesIdentifier")
+}
+
+func (id *aliasesIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatAliases)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*aliasesIdentifier)
+	return nil
+}
+
 func (id *aliasesIdentifier) ContainsIdentifier(identifier SubjectIdentifier) bool {
 	for _, v := range id.Ids {
 		if v.Format() == identifier.Format() && reflect.DeepEqual(v, identifier) {
@@ -62,6 +91,10 @@ func (id *aliasesIdentifier) AddIdentifier(identifier SubjectIdentifier) error {
 		return ErrNestedAliases
 	}
 
+	if identifier.Format() == FormatComplex {
+		return ErrNestedComplex
+	}
+
 	if id.ContainsIdentifier(identifier) {
 		return ErrDuplicatedIdentifier
 	}
@@ -0,0 +1,286 @@
+package secevsubid
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Event type URIs defined in the OpenID Continuous Access Evaluation Profile (CAEP) specification.
+// Reference: https://openid.net/specs/openid-caep-1_0.html
+const (
+	// EventTypeSessionRevoked is the event type URI of "Session Revoked" event.
+	EventTypeSessionRevoked = "https://schemas.openid.net/secevent/caep/event-type/session-revoked"
+	// EventTypeTokenClaimsChange is the event type URI of "Token Claims Change" event.
+	EventTypeTokenClaimsChange = "https://schemas.openid.net/secevent/caep/event-type/token-claims-change"
+	// EventTypeCredentialChange is the event type URI of "Credential Change" event.
+	EventTypeCredentialChange = "https://schemas.openid.net/secevent/caep/event-type/credential-change"
+	// EventTypeAssuranceLevelChange is the event type URI of "Assurance Level Change" event.
+	EventTypeAssuranceLevelChange = "https://schemas.openid.net/secevent/caep/event-type/assurance-level-change"
+	// EventTypeDeviceComplianceChange is the event type URI of "Device Compliance Change" event.
+	EventTypeDeviceComplianceChange = "https://schemas.openid.net/secevent/caep/event-type/device-compliance-change"
+)
+
+// InitiatingEntity is the value of "initiating_entity" member of CAEP events.
+type InitiatingEntity string
+
+const (
+	// InitiatingEntityAdmin means that an administrative action triggered the event.
+	InitiatingEntityAdmin = InitiatingEntity("admin")
+	// InitiatingEntityUser means that an end-user action triggered the event.
+	InitiatingEntityUser = InitiatingEntity("user")
+	// InitiatingEntityPolicy means that a policy evaluation triggered the event.
+	InitiatingEntityPolicy = InitiatingEntity("policy")
+	// InitiatingEntitySystem means that a system or platform assertion triggered the event.
+	InitiatingEntitySystem = InitiatingEntity("system")
+)
+
+// CAEPEventMetadata holds the members common to all CAEP events.
+type CAEPEventMetadata struct {
+	// EventTimestamp is the time at which the event occurred in seconds since the epoch.
+	// It is OPTIONAL and zero means that it is absent.
+	EventTimestamp int64 `json:"event_timestamp,omitempty"`
+	// InitiatingEntity describes the entity that invoked the event.
+	InitiatingEntity InitiatingEntity `json:"initiating_entity,omitempty"`
+	// ReasonAdmin is the reason for administrators keyed by language tag.
+	ReasonAdmin map[string]string `json:"reason_admin,omitempty"`
+	// ReasonUser is the reason for end-users keyed by language tag.
+	ReasonUser map[string]string `json:"reason_user,omitempty"`
+}
+
+func (m *CAEPEventMetadata) validate() error {
+	if m.EventTimestamp < 0 {
+		return invalidEventMember("event_timestamp", strconv.FormatInt(m.EventTimestamp, 10))
+	}
+
+	switch m.InitiatingEntity {
+	case "", InitiatingEntityAdmin, InitiatingEntityUser, InitiatingEntityPolicy, InitiatingEntitySystem:
+	default:
+		return invalidEventMember("initiating_entity", string(m.InitiatingEntity))
+	}
+
+	return nil
+}
+
+// SessionRevokedEvent is the "Session Revoked" event of CAEP.
+// Reference: https://openid.net/specs/openid-caep-1_0.html#name-session-revoked
+type SessionRevokedEvent struct {
+	CAEPEventMetadata
+}
+
+// EventType implements Event.
+func (e *SessionRevokedEvent) EventType() string {
+	return EventTypeSessionRevoked
+}
+
+// SubjectFormats implements Event.
+func (e *SessionRevokedEvent) SubjectFormats() []Format {
+	return []Format{FormatComplex, FormatOpaque, FormatIssuerSubject, FormatEmail, FormatPhoneNumber, FormatAccount, FormatJwtId, FormatSamlAssertionId}
+}
+
+// Validate implements Event.
+func (e *SessionRevokedEvent) Validate() error {
+	return e.CAEPEventMetadata.validate()
+}
+
+// TokenClaimsChangeEvent is the "Token Claims Change" event of CAEP.
+// Reference: https://openid.net/specs/openid-caep-1_0.html#name-token-claims-change
+type TokenClaimsChangeEvent struct {
+	CAEPEventMetadata
+	// Claims holds the claims whose values have changed and their new values.
+	Claims map[string]interface{} `json:"claims"`
+}
+
+// EventType implements Event.
+func (e *TokenClaimsChangeEvent) EventType() string {
+	return EventTypeTokenClaimsChange
+}
+
+// SubjectFormats implements Event.
+func (e *TokenClaimsChangeEvent) SubjectFormats() []Format {
+	return []Format{FormatComplex, FormatJwtId, FormatSamlAssertionId, FormatIssuerSubject, FormatOpaque}
+}
+
+// Validate implements Event.
+func (e *TokenClaimsChangeEvent) Validate() error {
+	if err := e.CAEPEventMetadata.validate(); err != nil {
+		return err
+	}
+
+	if len(e.Claims) == 0 {
+		return missingEventMember("claims")
+	}
+
+	return nil
+}
+
+// CredentialChangeType is the value of "change_type" member of "Credential Change" event.
+type CredentialChangeType string
+
+const (
+	// CredentialChangeTypeCreate means that the credential was created.
+	CredentialChangeTypeCreate = CredentialChangeType("create")
+	// CredentialChangeTypeRevoke means that the credential was revoked.
+	CredentialChangeTypeRevoke = CredentialChangeType("revoke")
+	// CredentialChangeTypeUpdate means that the credential was updated.
+	CredentialChangeTypeUpdate = CredentialChangeType("update")
+	// CredentialChangeTypeDelete means that the credential was deleted.
+	CredentialChangeTypeDelete = CredentialChangeType("delete")
+)
+
+// CredentialChangeEvent is the "Credential Change" event of CAEP.
+// Reference: https://openid.net/specs/openid-caep-1_0.html#name-credential-change
+type CredentialChangeEvent struct {
+	CAEPEventMetadata
+	// CredentialType is the type of the credential, e.g. "password", "fido2-platform".
+	CredentialType string `json:"credential_type"`
+	// ChangeType is the type of the change.
+	ChangeType CredentialChangeType `json:"change_type"`
+	// FriendlyName is the name of the credential shown to the user.
+	FriendlyName string `json:"friendly_name,omitempty"`
+	// X509Issuer is the issuer of the X.509 certificate.
+	X509Issuer string `json:"x509_issuer,omitempty"`
+	// X509Serial is the serial number of the X.509 certificate.
+	X509Serial string `json:"x509_serial,omitempty"`
+	// Fido2Aaguid is the AAGUID of the FIDO2 authenticator.
+	Fido2Aaguid string `json:"fido2_aaguid,omitempty"`
+}
+
+// EventType implements Event.
+func (e *CredentialChangeEvent) EventType() string {
+	return EventTypeCredentialChange
+}
+
+// SubjectFormats implements Event.
+func (e *CredentialChangeEvent) SubjectFormats() []Format {
+	return []Format{FormatComplex, FormatIssuerSubject, FormatEmail, FormatPhoneNumber, FormatAccount, FormatOpaque, FormatDid, FormatUri}
+}
+
+// Validate implements Event.
+func (e *CredentialChangeEvent) Validate() error {
+	if err := e.CAEPEventMetadata.validate(); err != nil {
+		return err
+	}
+
+	if e.CredentialType == "" {
+		return missingEventMember("credential_type")
+	}
+
+	switch e.ChangeType {
+	case "":
+		return missingEventMember("change_type")
+	case CredentialChangeTypeCreate, CredentialChangeTypeRevoke, CredentialChangeTypeUpdate, CredentialChangeTypeDelete:
+	default:
+		return invalidEventMember("change_type", string(e.ChangeType))
+	}
+
+	return nil
+}
+
+// AssuranceLevelChangeEvent is the "Assurance Level Change" event of CAEP.
+// Reference: https://openid.net/specs/openid-caep-1_0.html#name-assurance-level-change
+type AssuranceLevelChangeEvent struct {
+	CAEPEventMetadata
+	// Namespace is the namespace of the assurance levels, e.g. "RFC8176", "NIST-AAL".
+	Namespace string `json:"namespace"`
+	// CurrentLevel is the assurance level after the change.
+	CurrentLevel string `json:"current_level"`
+	// PreviousLevel is the assurance level before the change.
+	PreviousLevel string `json:"previous_level,omitempty"`
+	// ChangeDirection is "increase" or "decrease".
+	ChangeDirection string `json:"change_direction,omitempty"`
+}
+
+// EventType implements Event.
+func (e *AssuranceLevelChangeEvent) EventType() string {
+	return EventTypeAssuranceLevelChange
+}
+
+// SubjectFormats implements Event.
+func (e *AssuranceLevelChangeEvent) SubjectFormats() []Format {
+	return []Format{FormatComplex, FormatIssuerSubject, FormatEmail, FormatPhoneNumber, FormatAccount, FormatOpaque}
+}
+
+// Validate implements Event.
+func (e *AssuranceLevelChangeEvent) Validate() error {
+	if err := e.CAEPEventMetadata.validate(); err != nil {
+		return err
+	}
+
+	if e.Namespace == "" {
+		return missingEventMember("namespace")
+	}
+
+	if e.CurrentLevel == "" {
+		return missingEventMember("current_level")
+	}
+
+	switch e.ChangeDirection {
+	case "", "increase", "decrease":
+	default:
+		return invalidEventMember("change_direction", e.ChangeDirection)
+	}
+
+	return nil
+}
+
+// DeviceComplianceStatus is the value of "previous_status" and "current_status" member of "Device Compliance Change" event.
+type DeviceComplianceStatus string
+
+const (
+	// DeviceComplianceStatusCompliant means that the device is compliant.
+	DeviceComplianceStatusCompliant = DeviceComplianceStatus("compliant")
+	// DeviceComplianceStatusNotCompliant means that the device is not compliant.
+	DeviceComplianceStatusNotCompliant = DeviceComplianceStatus("not-compliant")
+)
+
+// DeviceComplianceChangeEvent is the "Device Compliance Change" event of CAEP.
+// Reference: https://openid.net/specs/openid-caep-1_0.html#name-device-compliance-change
+type DeviceComplianceChangeEvent struct {
+	CAEPEventMetadata
+	// PreviousStatus is the compliance status before the change.
+	PreviousStatus DeviceComplianceStatus `json:"previous_status"`
+	// CurrentStatus is the compliance status after the change.
+	CurrentStatus DeviceComplianceStatus `json:"current_status"`
+}
+
+// EventType implements Event.
+func (e *DeviceComplianceChangeEvent) EventType() string {
+	return EventTypeDeviceComplianceChange
+}
+
+// SubjectFormats implements Event.
+func (e *DeviceComplianceChangeEvent) SubjectFormats() []Format {
+	return []Format{FormatComplex, FormatIssuerSubject, FormatOpaque, FormatDid, FormatUri}
+}
+
+// Validate implements Event.
+func (e *DeviceComplianceChangeEvent) Validate() error {
+	if err := e.CAEPEventMetadata.validate(); err != nil {
+		return err
+	}
+
+	if err := validateDeviceComplianceStatus("previous_status", e.PreviousStatus); err != nil {
+		return err
+	}
+
+	return validateDeviceComplianceStatus("current_status", e.CurrentStatus)
+}
+
+func validateDeviceComplianceStatus(name string, s DeviceComplianceStatus) error {
+	switch s {
+	case "":
+		return missingEventMember(name)
+	case DeviceComplianceStatusCompliant, DeviceComplianceStatusNotCompliant:
+		return nil
+	}
+
+	return invalidEventMember(name, string(s))
+}
+
+func missingEventMember(name string) error {
+	return fmt.Errorf("%w: %s", ErrMissingEventMember, name)
+}
+
+func invalidEventMember(name string, value string) error {
+	return fmt.Errorf("%w: %s = %s", ErrInvalidEventMember, name, value)
+}
@@ -0,0 +1,137 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestCAEPEvents(t *testing.T) {
+	meta := secevsubid.CAEPEventMetadata{
+		EventTimestamp:   1615304991643,
+		InitiatingEntity: secevsubid.InitiatingEntityAdmin,
+		ReasonAdmin:      map[string]string{"en": "Policy Violation: C076E82F"},
+	}
+	tests := []struct {
+		name  string
+		event secevsubid.Event
+		json  string
+	}{
+		{
+			name:  "session revoked",
+			event: &secevsubid.SessionRevokedEvent{CAEPEventMetadata: meta},
+			json:  `{"event_timestamp":1615304991643,"initiating_entity":"admin","reason_admin":{"en":"Policy Violation: C076E82F"}}`,
+		},
+		{
+			name:  "token claims change",
+			event: &secevsubid.TokenClaimsChangeEvent{CAEPEventMetadata: meta, Claims: map[string]interface{}{"role": "ro-admin"}},
+			json:  `{"event_timestamp":1615304991643,"initiating_entity":"admin","reason_admin":{"en":"Policy Violation: C076E82F"},"claims":{"role":"ro-admin"}}`,
+		},
+		{
+			name: "credential change",
+			event: &secevsubid.CredentialChangeEvent{
+				CAEPEventMetadata: meta,
+				CredentialType:    "fido2-roaming",
+				ChangeType:        secevsubid.CredentialChangeTypeCreate,
+				FriendlyName:      "Jane's USB authenticator",
+				Fido2Aaguid:       "accced6a-63f5-490a-9eea-e59bc1896cfc",
+			},
+			json: `{"event_timestamp":1615304991643,"initiating_entity":"admin","reason_admin":{"en":"Policy Violation: C076E82F"},"credential_type":"fido2-roaming","change_type":"create","friendly_name":"Jane's USB authenticator","fido2_aaguid":"accced6a-63f5-490a-9eea-e59bc1896cfc"}`,
+		},
+		{
+			name: "assurance level change",
+			event: &secevsubid.AssuranceLevelChangeEvent{
+				CAEPEventMetadata: meta,
+				Namespace:         "NIST-AAL",
+				CurrentLevel:      "nist-aal2",
+				PreviousLevel:     "nist-aal1",
+				ChangeDirection:   "increase",
+			},
+			json: `{"event_timestamp":1615304991643,"initiating_entity":"admin","reason_admin":{"en":"Policy Violation: C076E82F"},"namespace":"NIST-AAL","current_level":"nist-aal2","previous_level":"nist-aal1","change_direction":"increase"}`,
+		},
+		{
+			name: "device compliance change",
+			event: &secevsubid.DeviceComplianceChangeEvent{
+				CAEPEventMetadata: meta,
+				PreviousStatus:    secevsubid.DeviceComplianceStatusCompliant,
+				CurrentStatus:     secevsubid.DeviceComplianceStatusNotCompliant,
+			},
+			json: `{"event_timestamp":1615304991643,"initiating_entity":"admin","reason_admin":{"en":"Policy Violation: C076E82F"},"previous_status":"compliant","current_status":"not-compliant"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := secevsubid.EncodeEvents(tt.event)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got := string(events[tt.event.EventType()]); got != tt.json {
+				t.Errorf("EncodeEvents() got = %s, want %s", got, tt.json)
+			}
+
+			decoded, err := secevsubid.DecodeEvents(map[string]json.RawMessage{tt.event.EventType(): json.RawMessage(tt.json)})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, []secevsubid.Event{tt.event}) {
+				t.Errorf("DecodeEvents() got = %v, want %v", decoded[0], tt.event)
+			}
+		})
+	}
+}
+
+func TestCAEPEventsWithInvalidMember(t *testing.T) {
+	meta := secevsubid.CAEPEventMetadata{EventTimestamp: 1615304991643}
+	tests := []struct {
+		name    string
+		event   secevsubid.Event
+		wantErr error
+	}{
+		{
+			name:    "token claims change without claims",
+			event:   &secevsubid.TokenClaimsChangeEvent{CAEPEventMetadata: meta},
+			wantErr: secevsubid.ErrMissingEventMember,
+		},
+		{
+			name:    "credential change without credential type",
+			event:   &secevsubid.CredentialChangeEvent{CAEPEventMetadata: meta, ChangeType: secevsubid.CredentialChangeTypeCreate},
+			wantErr: secevsubid.ErrMissingEventMember,
+		},
+		{
+			name:    "credential change with unknown change type",
+			event:   &secevsubid.CredentialChangeEvent{CAEPEventMetadata: meta, CredentialType: "password", ChangeType: "replace"},
+			wantErr: secevsubid.ErrInvalidEventMember,
+		},
+		{
+			name:    "assurance level change without namespace",
+			event:   &secevsubid.AssuranceLevelChangeEvent{CAEPEventMetadata: meta, CurrentLevel: "nist-aal2"},
+			wantErr: secevsubid.ErrMissingEventMember,
+		},
+		{
+			name:    "device compliance change with unknown status",
+			event:   &secevsubid.DeviceComplianceChangeEvent{CAEPEventMetadata: meta, PreviousStatus: "compliant", CurrentStatus: "unknown"},
+			wantErr: secevsubid.ErrInvalidEventMember,
+		},
+		{
+			name:    "without event timestamp",
+			event:   &secevsubid.SessionRevokedEvent{},
+			wantErr: nil,
+		},
+		{
+			name:    "negative event timestamp",
+			event:   &secevsubid.SessionRevokedEvent{CAEPEventMetadata: secevsubid.CAEPEventMetadata{EventTimestamp: -1}},
+			wantErr: secevsubid.ErrInvalidEventMember,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.event.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
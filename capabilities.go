@@ -0,0 +1,183 @@
+package secevsubid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities describes subject identifiers a peer (transmitter or receiver) accepts.
+type Capabilities struct {
+	// Formats is the list of supported formats except for aliases and complex.
+	// If empty, all formats are supported.
+	Formats []Format
+	// Aliases indicates whether Aliases Identifier Format is supported.
+	Aliases bool
+	// Complex indicates whether Complex Subject is supported.
+	Complex bool
+	// EmailAsAccount indicates whether Email Identifier may be converted to Account Identifier with "acct:" scheme
+	// when email is not supported but account is. The peer must treat both as the same account for this to be safe.
+	EmailAsAccount bool
+}
+
+// CapabilitiesFromConfiguration returns Capabilities declared by "subject_formats_supported" of TransmitterConfiguration.
+// If the transmitter does not declare supported formats, all formats are supported.
+func CapabilitiesFromConfiguration(c *TransmitterConfiguration) Capabilities {
+	all := len(c.SubjectFormatsSupported) == 0
+	caps := Capabilities{
+		Aliases: all || containsFormat(c.SubjectFormatsSupported, FormatAliases),
+		Complex: all || containsFormat(c.SubjectFormatsSupported, FormatComplex),
+	}
+	for _, f := range c.SubjectFormatsSupported {
+		if f != FormatAliases && f != FormatComplex {
+			caps.Formats = append(caps.Formats, f)
+		}
+	}
+
+	return caps
+}
+
+// Supports returns whether the format is supported.
+func (c Capabilities) Supports(f Format) bool {
+	switch f {
+	case FormatAliases:
+		return c.Aliases
+	case FormatComplex:
+		return c.Complex
+	}
+
+	return len(c.Formats) == 0 || containsFormat(c.Formats, f)
+}
+
+// Negotiate converts or filters the identifier to the representation the peer accepts.
+//   - Unsupported members of AliasesIdentifier are removed.
+//     If aliases is not supported, the first acceptable member is returned.
+//   - If complex is not supported or any member is not acceptable, ComplexIdentifier falls back to its "user" member.
+//   - If EmailAsAccount is set, Email Identifier is converted to Account Identifier with "acct:" scheme when only account is supported.
+//
+// If no acceptable representation exists, it returns an error wrapping ErrNoAcceptableSubject with the reasons.
+func Negotiate(id SubjectIdentifier, caps Capabilities) (SubjectIdentifier, error) {
+	if id == nil {
+		return nil, ErrNoSubject
+	}
+
+	var reasons []string
+	v := negotiate(id, caps, &reasons)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoAcceptableSubject, strings.Join(reasons, "; "))
+	}
+
+	return v, nil
+}
+
+func negotiate(id SubjectIdentifier, caps Capabilities, reasons *[]string) SubjectIdentifier {
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		return negotiateAliases(v, caps, reasons)
+	case ComplexIdentifier:
+		return negotiateComplex(v, caps, reasons)
+	}
+
+	if caps.Supports(id.Format()) {
+		return id
+	}
+
+	if e, ok := id.(EmailIdentifier); ok && caps.EmailAsAccount && caps.Supports(FormatAccount) {
+		if a, err := NewAccountIdentifier("acct:" + e.Email()); err == nil {
+			return a
+		}
+	}
+
+	*reasons = append(*reasons, fmt.Sprintf("%s is not supported", id.Format()))
+	return nil
+}
+
+func negotiateAliases(id AliasesIdentifier, caps Capabilities, reasons *[]string) SubjectIdentifier {
+	var ids []SubjectIdentifier
+	for _, m := range id.Identifiers() {
+		v := negotiate(m, caps, reasons)
+		if v == nil {
+			continue
+		}
+		if !caps.Aliases {
+			return v
+		}
+		if !containsIdentifier(ids, v) {
+			ids = append(ids, v)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		*reasons = append(*reasons, "no member of aliases is acceptable")
+		return nil
+	case 1:
+		return ids[0]
+	}
+
+	a, err := NewAliasesIdentifier(ids...)
+	if err != nil {
+		*reasons = append(*reasons, err.Error())
+		return nil
+	}
+	return a
+}
+
+func negotiateComplex(id ComplexIdentifier, caps Capabilities, reasons *[]string) SubjectIdentifier {
+	if caps.Complex {
+		if v := negotiateComplexMembers(id, caps, reasons); v != nil {
+			return v
+		}
+	} else {
+		*reasons = append(*reasons, "complex is not supported")
+	}
+
+	if u := id.User(); u != nil {
+		return negotiate(u, caps, reasons)
+	}
+
+	*reasons = append(*reasons, "complex has no user member to fall back to")
+	return nil
+}
+
+func negotiateComplexMembers(id ComplexIdentifier, caps Capabilities, reasons *[]string) SubjectIdentifier {
+	m := ComplexMembers{}
+	targets := []struct {
+		src SubjectIdentifier
+		dst *SubjectIdentifier
+	}{
+		{id.User(), &m.User},
+		{id.Device(), &m.Device},
+		{id.Session(), &m.Session},
+		{id.Application(), &m.Application},
+		{id.Tenant(), &m.Tenant},
+		{id.OrgUnit(), &m.OrgUnit},
+		{id.Group(), &m.Group},
+	}
+	for _, t := range targets {
+		if t.src == nil {
+			continue
+		}
+		v := negotiate(t.src, caps, reasons)
+		if v == nil {
+			return nil
+		}
+		*t.dst = v
+	}
+
+	c, err := NewComplexIdentifier(m)
+	if err != nil {
+		*reasons = append(*reasons, err.Error())
+		return nil
+	}
+	return c
+}
+
+func containsIdentifier(ids []SubjectIdentifier, id SubjectIdentifier) bool {
+	for _, v := range ids {
+		if identifierEquals(v, id) {
+			return true
+		}
+	}
+
+	return false
+}
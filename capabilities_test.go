@@ -0,0 +1,123 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	acct, _ := secevsubid.NewAccountIdentifier("acct:user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	device, _ := secevsubid.NewOpaqueIdentifier("device")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, phone, issSub)
+	phoneAndIssSub, _ := secevsubid.NewAliasesIdentifier(phone, issSub)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: email, Device: device})
+	deviceOnly, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{Device: device})
+
+	tests := []struct {
+		name    string
+		id      secevsubid.SubjectIdentifier
+		caps    secevsubid.Capabilities
+		want    secevsubid.SubjectIdentifier
+		wantErr error
+	}{
+		{
+			name: "supported",
+			id:   email,
+			caps: secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatEmail}},
+			want: email,
+		},
+		{
+			name: "all formats supported",
+			id:   complexSubject,
+			caps: secevsubid.Capabilities{Complex: true},
+			want: complexSubject,
+		},
+		{
+			name: "email to account",
+			id:   email,
+			caps: secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatAccount}, EmailAsAccount: true},
+			want: acct,
+		},
+		{
+			name:    "email to account not allowed",
+			id:      email,
+			caps:    secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatAccount}},
+			wantErr: secevsubid.ErrNoAcceptableSubject,
+		},
+		{
+			name: "aliases members filtered",
+			id:   aliases,
+			caps: secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatPhoneNumber, secevsubid.FormatIssuerSubject}, Aliases: true},
+			want: phoneAndIssSub,
+		},
+		{
+			name: "aliases not supported",
+			id:   aliases,
+			caps: secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatPhoneNumber, secevsubid.FormatIssuerSubject}},
+			want: phone,
+		},
+		{
+			name: "complex not supported falls back to user",
+			id:   complexSubject,
+			caps: secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatEmail}},
+			want: email,
+		},
+		{
+			name: "complex member not supported falls back to user",
+			id:   complexSubject,
+			caps: secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatEmail}, Complex: true},
+			want: email,
+		},
+		{
+			name:    "complex without user",
+			id:      deviceOnly,
+			caps:    secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatOpaque}},
+			wantErr: secevsubid.ErrNoAcceptableSubject,
+		},
+		{
+			name:    "no acceptable member",
+			id:      aliases,
+			caps:    secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatDid}, Aliases: true},
+			wantErr: secevsubid.ErrNoAcceptableSubject,
+		},
+		{
+			name:    "nil",
+			id:      nil,
+			wantErr: secevsubid.ErrNoSubject,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.Negotiate(tt.id, tt.caps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Negotiate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Negotiate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesFromConfiguration(t *testing.T) {
+	c := &secevsubid.TransmitterConfiguration{
+		Issuer:                  "https://issuer.example.com/",
+		SubjectFormatsSupported: []secevsubid.Format{secevsubid.FormatEmail, secevsubid.FormatAliases},
+	}
+	want := secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatEmail}, Aliases: true}
+	if got := secevsubid.CapabilitiesFromConfiguration(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("CapabilitiesFromConfiguration() = %v, want %v", got, want)
+	}
+
+	c.SubjectFormatsSupported = nil
+	want = secevsubid.Capabilities{Aliases: true, Complex: true}
+	if got := secevsubid.CapabilitiesFromConfiguration(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("CapabilitiesFromConfiguration() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,241 @@
+package secevsubid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// CBOR major types used by subject identifiers.
+const (
+	cborTextString = 3
+	cborArray      = 4
+	cborMap        = 5
+)
+
+// cborMaxDepth limits nesting of arrays and maps in decoding. Complex Subject with aliases members is the deepest structure.
+const cborMaxDepth = 8
+
+// EncodeCBOR returns CBOR (RFC 8949) representation of the identifier.
+// The structure is the same as JSON representation: maps with text string keys, text string values and arrays for aliases.
+// The encoding is deterministic as defined in RFC 8949 section 4.2.1, i.e. shortest lengths, definite lengths and sorted map keys.
+// Reference: https://www.rfc-editor.org/rfc/rfc8949.html#section-4.2.1
+func EncodeCBOR(id SubjectIdentifier) ([]byte, error) {
+	m, err := identifierMap(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = encodeCBOR(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		writeCBORHead(buf, cborTextString, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, e := range v {
+			if err := encodeCBOR(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// Text string keys are sorted by their encoded form: shorter length first, then bytewise.
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+
+		writeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, k := range keys {
+			writeCBORHead(buf, cborTextString, uint64(len(k)))
+			buf.WriteString(k)
+			if err := encodeCBOR(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value for cbor: %T", v)
+	}
+
+	return nil
+}
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	h := major << 5
+	switch {
+	case n < 24:
+		buf.WriteByte(h | byte(n))
+	case n <= 0xff:
+		buf.WriteByte(h | 24)
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(h | 25)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= 0xffffffff:
+		buf.WriteByte(h | 26)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(h | 27)
+		_ = binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// DecodeCBOR decodes CBOR representation to the appropriate SubjectIdentifier instance.
+// Only definite length maps, arrays and text strings are accepted, and the identifier is validated in the same way as DecodeJSON.
+func DecodeCBOR(b []byte) (SubjectIdentifier, error) {
+	d := &cborDecoder{b: b}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(b) {
+		return nil, fmt.Errorf("%w: trailing data", ErrMalformedCBOR)
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: not map", ErrMalformedCBOR)
+	}
+	return decodeIdentifier(m)
+}
+
+type cborDecoder struct {
+	b   []byte
+	off int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, fmt.Errorf("%w: too deep", ErrMalformedCBOR)
+	}
+
+	major, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborTextString:
+		return d.text(n)
+	case cborArray:
+		// Each element takes at least one byte.
+		if n > uint64(len(d.b)-d.off) {
+			return nil, fmt.Errorf("%w: unexpected end", ErrMalformedCBOR)
+		}
+		vs := make([]interface{}, n)
+		for i := range vs {
+			if vs[i], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return vs, nil
+	case cborMap:
+		if n > uint64(len(d.b)-d.off)/2 {
+			return nil, fmt.Errorf("%w: unexpected end", ErrMalformedCBOR)
+		}
+		m := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			km, kn, err := d.head()
+			if err != nil {
+				return nil, err
+			}
+			if km != cborTextString {
+				return nil, fmt.Errorf("%w: map key is not text string", ErrMalformedCBOR)
+			}
+			k, err := d.text(kn)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m[k]; ok {
+				return nil, fmt.Errorf("%w: duplicated key %s", ErrMalformedCBOR, k)
+			}
+			if m[k], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported major type %d", ErrMalformedCBOR, major)
+}
+
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.off >= len(d.b) {
+		return 0, 0, fmt.Errorf("%w: unexpected end", ErrMalformedCBOR)
+	}
+
+	ib := d.b[d.off]
+	d.off++
+	major, info := ib>>5, ib&0x1f
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+
+	var size int
+	switch info {
+	case 24:
+		size = 1
+	case 25:
+		size = 2
+	case 26:
+		size = 4
+	case 27:
+		size = 8
+	default:
+		return 0, 0, fmt.Errorf("%w: indefinite length or reserved additional information", ErrMalformedCBOR)
+	}
+	if len(d.b)-d.off < size {
+		return 0, 0, fmt.Errorf("%w: unexpected end", ErrMalformedCBOR)
+	}
+
+	var n uint64
+	for _, c := range d.b[d.off : d.off+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.off += size
+	return major, n, nil
+}
+
+func (d *cborDecoder) text(n uint64) (string, error) {
+	if n > uint64(len(d.b)-d.off) {
+		return "", fmt.Errorf("%w: unexpected end", ErrMalformedCBOR)
+	}
+
+	s := d.b[d.off : d.off+int(n)]
+	d.off += int(n)
+	if !utf8.Valid(s) {
+		return "", fmt.Errorf("%w: invalid utf-8 text string", ErrMalformedCBOR)
+	}
+	return string(s), nil
+}
+
+// MarshalCBOR returns CBOR representation of the SubjectIdentifier held internally. See EncodeCBOR for details.
+func (w *Wrapper) MarshalCBOR() ([]byte, error) {
+	return EncodeCBOR(w.v)
+}
+
+// UnmarshalCBOR decodes CBOR representation via DecodeCBOR.
+func (w *Wrapper) UnmarshalCBOR(b []byte) error {
+	id, err := DecodeCBOR(b)
+	if err != nil {
+		return err
+	}
+
+	w.v = id
+	return nil
+}
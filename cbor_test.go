@@ -0,0 +1,100 @@
+package secevsubid_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCBOR(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	long, _ := secevsubid.NewOpaqueIdentifier(strings.Repeat("x", 300))
+	saml, _ := secevsubid.NewSamlAssertionIdIdentifier("https://idp.example.com/", "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub, phone)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: aliases, Tenant: long})
+
+	tests := []struct {
+		name string
+		id   secevsubid.SubjectIdentifier
+		want string
+	}{
+		{
+			name: "email",
+			id:   email,
+			// {"email": "user@example.com", "format": "email"}
+			want: "a2" + "65" + hex.EncodeToString([]byte("email")) + "70" + hex.EncodeToString([]byte("user@example.com")) +
+				"66" + hex.EncodeToString([]byte("format")) + "65" + hex.EncodeToString([]byte("email")),
+		},
+		{name: "long value", id: long},
+		{name: "saml", id: saml},
+		{name: "aliases", id: aliases},
+		{name: "complex", id: complexSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.EncodeCBOR(tt.id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tt.want != "" && hex.EncodeToString(got) != tt.want {
+				t.Errorf("EncodeCBOR() = %x, want %s", got, tt.want)
+			}
+			again, _ := secevsubid.EncodeCBOR(tt.id)
+			if !reflect.DeepEqual(got, again) {
+				t.Errorf("EncodeCBOR() = %x, want deterministic %x", again, got)
+			}
+
+			decoded, err := secevsubid.DecodeCBOR(got)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, tt.id) {
+				t.Errorf("DecodeCBOR() = %v, want %v", decoded, tt.id)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name    string
+		hex     string
+		wantErr error
+	}{
+		{name: "empty", hex: "", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "not map", hex: "65" + hex.EncodeToString([]byte("email")), wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "integer key", hex: "a10101", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "indefinite map", hex: "bf", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "truncated", hex: "a166666f726d6174", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "huge length", hex: "a166666f726d61747bffffffffffffffff", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "invalid utf-8", hex: "a166666f726d617461ff", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "trailing data", hex: "a0" + "00", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "duplicated key", hex: "a2" + "6161" + "6161" + "6161" + "6161", wantErr: secevsubid.ErrMalformedCBOR},
+		{name: "no format", hex: "a0", wantErr: secevsubid.ErrNoFormat},
+		// {"format": "email", "email": ["x"]}
+		{name: "not string value", hex: "a2" + "66" + hex.EncodeToString([]byte("format")) + "65" + hex.EncodeToString([]byte("email")) + "65" + hex.EncodeToString([]byte("email")) + "816178", wantErr: secevsubid.ErrEmptyEmail},
+		// {"format": "aliases", "identifiers": "x"}
+		{name: "not array identifiers", hex: "a2" + "66" + hex.EncodeToString([]byte("format")) + "67" + hex.EncodeToString([]byte("aliases")) + "6b" + hex.EncodeToString([]byte("identifiers")) + "6178"},
+		{name: "too deep", hex: strings.Repeat("81", 20) + "a0", wantErr: secevsubid.ErrMalformedCBOR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := hex.DecodeString(tt.hex)
+			_, err := secevsubid.DecodeCBOR(b)
+			if err == nil {
+				t.Errorf("DecodeCBOR() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeCBOR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
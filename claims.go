@@ -0,0 +1,110 @@
+package secevsubid
+
+import (
+	"fmt"
+)
+
+// Claim names of OpenID Connect ID token used by AliasesFromClaims.
+const (
+	claimIssuer              = "iss"
+	claimSubject             = "sub"
+	claimEmail               = "email"
+	claimEmailVerified       = "email_verified"
+	claimPhoneNumber         = "phone_number"
+	claimPhoneNumberVerified = "phone_number_verified"
+)
+
+// ClaimsRules configures which claims AliasesFromClaims includes. The zero value includes only verified email and phone number.
+type ClaimsRules struct {
+	// IncludeUnverifiedEmail includes "email" even if "email_verified" is not true.
+	IncludeUnverifiedEmail bool
+	// IncludeUnverifiedPhoneNumber includes "phone_number" even if "phone_number_verified" is not true.
+	IncludeUnverifiedPhoneNumber bool
+	// ExcludeEmail never includes "email".
+	ExcludeEmail bool
+	// ExcludePhoneNumber never includes "phone_number".
+	ExcludePhoneNumber bool
+}
+
+// AliasesFromClaims creates AliasesIdentifier from validated OpenID Connect ID token claims.
+// It holds Issuer and Subject Identifier from "iss" and "sub" claims, which are required,
+// and Email and Phone Number Identifier from "email" and "phone_number" claims following the rules.
+// Verification claims are accepted as boolean true or string "true". If the argument "rules" is nil, the zero value is used.
+// Reference: https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
+func AliasesFromClaims(claims map[string]interface{}, rules *ClaimsRules) (AliasesIdentifier, error) {
+	if rules == nil {
+		rules = &ClaimsRules{}
+	}
+
+	iss, err := stringClaim(claims, claimIssuer)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := stringClaim(claims, claimSubject)
+	if err != nil {
+		return nil, err
+	}
+	issSub, err := NewIssuerSubjectIdentifier(iss, sub)
+	if err != nil {
+		return nil, err
+	}
+	ids := []SubjectIdentifier{issSub}
+
+	email, err := stringClaim(claims, claimEmail)
+	if err != nil {
+		return nil, err
+	}
+	if email != "" && rules.includesEmail(claims) {
+		id, err := NewEmailIdentifier(email)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	phone, err := stringClaim(claims, claimPhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if phone != "" && rules.includesPhoneNumber(claims) {
+		id, err := NewPhoneNumberIdentifier(phone)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return NewAliasesIdentifier(ids...)
+}
+
+func (r *ClaimsRules) includesEmail(claims map[string]interface{}) bool {
+	return !r.ExcludeEmail && (r.IncludeUnverifiedEmail || verifiedClaim(claims, claimEmailVerified))
+}
+
+func (r *ClaimsRules) includesPhoneNumber(claims map[string]interface{}) bool {
+	return !r.ExcludePhoneNumber && (r.IncludeUnverifiedPhoneNumber || verifiedClaim(claims, claimPhoneNumberVerified))
+}
+
+func stringClaim(claims map[string]interface{}, name string) (string, error) {
+	v, ok := claims[name]
+	if !ok || v == nil {
+		return "", nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid %s claim: %v", name, v)
+	}
+	return s, nil
+}
+
+func verifiedClaim(claims map[string]interface{}, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+
+	return false
+}
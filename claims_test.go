@@ -0,0 +1,93 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestAliasesFromClaims(t *testing.T) {
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	all, _ := secevsubid.NewAliasesIdentifier(issSub, email, phone)
+	issSubOnly, _ := secevsubid.NewAliasesIdentifier(issSub)
+	issSubAndEmail, _ := secevsubid.NewAliasesIdentifier(issSub, email)
+	issSubAndPhone, _ := secevsubid.NewAliasesIdentifier(issSub, phone)
+
+	claims := func(s string) map[string]interface{} {
+		m := make(map[string]interface{})
+		_ = json.Unmarshal([]byte(s), &m)
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		rules   *secevsubid.ClaimsRules
+		want    secevsubid.AliasesIdentifier
+		wantErr error
+	}{
+		{
+			name:   "verified",
+			claims: claims(`{"iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com","email_verified":true,"phone_number":"+12065550100","phone_number_verified":true}`),
+			want:   all,
+		},
+		{
+			name:   "verified as string",
+			claims: claims(`{"iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com","email_verified":"true","phone_number":"+12065550100","phone_number_verified":"false"}`),
+			want:   issSubAndEmail,
+		},
+		{
+			name:   "unverified",
+			claims: claims(`{"iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com","phone_number":"+12065550100","phone_number_verified":false}`),
+			want:   issSubOnly,
+		},
+		{
+			name:   "include unverified",
+			claims: claims(`{"iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com","phone_number":"+12065550100"}`),
+			rules:  &secevsubid.ClaimsRules{IncludeUnverifiedPhoneNumber: true},
+			want:   issSubAndPhone,
+		},
+		{
+			name:   "exclude email",
+			claims: claims(`{"iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com","email_verified":true,"phone_number":"+12065550100","phone_number_verified":true}`),
+			rules:  &secevsubid.ClaimsRules{ExcludeEmail: true},
+			want:   issSubAndPhone,
+		},
+		{
+			name:    "no sub",
+			claims:  claims(`{"iss":"https://issuer.example.com/","email":"user@example.com","email_verified":true}`),
+			wantErr: secevsubid.ErrEmptySubject,
+		},
+		{
+			name:    "no iss",
+			claims:  claims(`{"sub":"145234573"}`),
+			wantErr: secevsubid.ErrEmptyIssuer,
+		},
+		{
+			name:   "invalid email claim",
+			claims: claims(`{"iss":"https://issuer.example.com/","sub":"145234573","email":["user@example.com"],"email_verified":true}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.AliasesFromClaims(tt.claims, tt.rules)
+			if tt.want == nil && tt.wantErr == nil {
+				if err == nil {
+					t.Errorf("AliasesFromClaims() error = nil, want error")
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AliasesFromClaims() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AliasesFromClaims() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package secevsubid
+
+import (
+	"encoding/xml"
+	"log/slog"
+	"reflect"
+)
+
+// ComplexIdentifier is one of the sub-interfaces of SubjectIdentifier.
+// It represents the "Complex Subject" defined in the OpenID Shared Signals Framework specification.
+// Each member is a SubjectIdentifier which is not ComplexIdentifier.
+// Unlike other formats, JSON representation of ComplexIdentifier has no "format" field.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-complex-subjects
+type ComplexIdentifier interface {
+	// Format returns name of the format actually held by the instance.
+	// The value is the fixed value "complex".
+	Format() Format
+	// User returns "user" member held by the instance.
+	User() SubjectIdentifier
+	// Device returns "device" member held by the instance.
+	Device() SubjectIdentifier
+	// Session returns "session" member held by the instance.
+	Session() SubjectIdentifier
+	// Application returns "application" member held by the instance.
+	Application() SubjectIdentifier
+	// Tenant returns "tenant" member held by the instance.
+	Tenant() SubjectIdentifier
+	// OrgUnit returns "org_unit" member held by the instance.
+	OrgUnit() SubjectIdentifier
+	// Group returns "group" member held by the instance.
+	Group() SubjectIdentifier
+	// Members returns all members held by the instance keyed by member name.
+	Members() map[string]SubjectIdentifier
+	// Matches returns whether the argument matches the instance.
+	// All members held by the instance must exist in the argument with the same content.
+	Matches(subject SubjectIdentifier) bool
+	// Validate values held and returns an error if there is a problem.
+	Validate() error
+}
+
+// ComplexMembers holds members for creating ComplexIdentifier.
+// Members which are nil are omitted.
+type ComplexMembers struct {
+	User        SubjectIdentifier
+	Device      SubjectIdentifier
+	Session     SubjectIdentifier
+	Application SubjectIdentifier
+	Tenant      SubjectIdentifier
+	OrgUnit     SubjectIdentifier
+	Group       SubjectIdentifier
+}
+
+type complexIdentifier struct {
+	U *Wrapper `json:"user,omitempty"`
+	D *Wrapper `json:"device,omitempty"`
+	S *Wrapper `json:"session,omitempty"`
+	A *Wrapper `json:"application,omitempty"`
+	T *Wrapper `json:"tenant,omitempty"`
+	O *Wrapper `json:"org_unit,omitempty"`
+	G *Wrapper `json:"group,omitempty"`
+}
+
+func (id *complexIdentifier) Format() Format {
+	return FormatComplex
+}
+
+func (id *complexIdentifier) User() SubjectIdentifier {
+	return unwrap(id.U)
+}
+
+func (id *complexIdentifier) Device() SubjectIdentifier {
+	return unwrap(id.D)
+}
+
+func (id *complexIdentifier) Session() SubjectIdentifier {
+	return unwrap(id.S)
+}
+
+func (id *complexIdentifier) Application() SubjectIdentifier {
+	return unwrap(id.A)
+}
+
+func (id *complexIdentifier) Tenant() SubjectIdentifier {
+	return unwrap(id.T)
+}
+
+func (id *complexIdentifier) OrgUnit() SubjectIdentifier {
+	return unwrap(id.O)
+}
+
+func (id *complexIdentifier) Group() SubjectIdentifier {
+	return unwrap(id.G)
+}
+
+func (id *complexIdentifier) Members() map[string]SubjectIdentifier {
+	m := make(map[string]SubjectIdentifier)
+	for name, w := range id.wrappers() {
+		if w != nil {
+			m[name] = w.Value()
+		}
+	}
+	return m
+}
+
+func (id *complexIdentifier) wrappers() map[string]*Wrapper {
+	return map[string]*Wrapper{
+		fieldUser:        id.U,
+		fieldDevice:      id.D,
+		fieldSession:     id.S,
+		fieldApplication: id.A,
+		fieldTenant:      id.T,
+		fieldOrgUnit:     id.O,
+		fieldGroup:       id.G,
+	}
+}
+
+func (id *complexIdentifier) Matches(subject SubjectIdentifier) bool {
+	other, ok := subject.(ComplexIdentifier)
+	if !ok {
+		return false
+	}
+
+	om := other.Members()
+	for name, v := range id.Members() {
+		o, ok := om[name]
+		if !ok || !identifierEquals(v, o) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (id *complexIdentifier) Validate() error {
+	members := id.Members()
+	if len(members) == 0 {
+		return ErrEmptyComplex
+	}
+
+	for _, v := range members {
+		if v == nil {
+			return ErrEmptyComplex
+		}
+		if v.Format() == FormatComplex {
+			return ErrNestedComplex
+		}
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (id *complexIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *complexIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *complexIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *complexIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "complexIdentifier")
+}
+
+func (id *complexIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatComplex)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*complexIdentifier)
+	return nil
+}
+
+// NewComplexIdentifier creates new instance of ComplexIdentifier.
+// At least one member is required. If any member is invalid or ComplexIdentifier, this function returns error.
+func NewComplexIdentifier(members ComplexMembers) (ComplexIdentifier, error) {
+	id := &complexIdentifier{
+		U: wrap(members.User),
+		D: wrap(members.Device),
+		S: wrap(members.Session),
+		A: wrap(members.Application),
+		T: wrap(members.Tenant),
+		O: wrap(members.OrgUnit),
+		G: wrap(members.Group),
+	}
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// MatchSubject returns whether the subject matches the pattern.
+// If the pattern is ComplexIdentifier, all of its members must match the members of the subject.
+// If the pattern is not ComplexIdentifier and the subject is ComplexIdentifier, any member of the subject must match the pattern.
+// AliasesIdentifier matches when any of its identifiers matches.
+func MatchSubject(pattern SubjectIdentifier, subject SubjectIdentifier) bool {
+	if pattern == nil || subject == nil {
+		return false
+	}
+
+	if c, ok := pattern.(ComplexIdentifier); ok {
+		return c.Matches(subject)
+	}
+
+	if c, ok := subject.(ComplexIdentifier); ok {
+		for _, v := range c.Members() {
+			if identifierEquals(pattern, v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return identifierEquals(pattern, subject)
+}
+
+func identifierEquals(a SubjectIdentifier, b SubjectIdentifier) bool {
+	if aa, ok := a.(AliasesIdentifier); ok {
+		for _, v := range aa.Identifiers() {
+			if identifierEquals(v, b) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ba, ok := b.(AliasesIdentifier); ok {
+		return ba.ContainsIdentifier(a)
+	}
+
+	return a.Format() == b.Format() && reflect.DeepEqual(a, b)
+}
+
+func wrap(id SubjectIdentifier) *Wrapper {
+	if id == nil {
+		return nil
+	}
+	return NewWrapper(id)
+}
+
+func unwrap(w *Wrapper) SubjectIdentifier {
+	if w == nil {
+		return nil
+	}
+	return w.Value()
+}
@@ -0,0 +1,265 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestNewComplexIdentifier(t *testing.T) {
+	user, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	device, _ := secevsubid.NewIssuerSubjectIdentifier("https://idp.example.com/123456789/", "e9297990-14d2-42ec-a4a9-4036db86509a")
+	aliases, _ := secevsubid.NewAliasesIdentifier(user)
+	complexId, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: user})
+	invalid := &invalidIdentifier{}
+
+	tests := []struct {
+		name    string
+		members secevsubid.ComplexMembers
+		wantErr error
+	}{
+		{
+			name:    "with members",
+			members: secevsubid.ComplexMembers{User: user, Device: device},
+			wantErr: nil,
+		},
+		{
+			name:    "with aliases member",
+			members: secevsubid.ComplexMembers{User: aliases},
+			wantErr: nil,
+		},
+		{
+			name:    "without members",
+			members: secevsubid.ComplexMembers{},
+			wantErr: secevsubid.ErrEmptyComplex,
+		},
+		{
+			name:    "nested complex",
+			members: secevsubid.ComplexMembers{User: user, Group: complexId},
+			wantErr: secevsubid.ErrNestedComplex,
+		},
+		{
+			name:    "invalid member",
+			members: secevsubid.ComplexMembers{User: invalid},
+			wantErr: secevsubid.ErrEmptyId,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.NewComplexIdentifier(tt.members)
+			if err != tt.wantErr {
+				t.Errorf("NewComplexIdentifier() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Format() != secevsubid.FormatComplex {
+				t.Errorf("invalid format: got = %s, want = %s", got.Format(), secevsubid.FormatComplex)
+			}
+		})
+	}
+}
+
+type invalidIdentifier struct{}
+
+func (id *invalidIdentifier) Format() secevsubid.Format {
+	return secevsubid.FormatOpaque
+}
+
+func (id *invalidIdentifier) Validate() error {
+	return secevsubid.ErrEmptyId
+}
+
+func TestComplexIdentifier_MarshalJSON(t *testing.T) {
+	user, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	device, _ := secevsubid.NewIssuerSubjectIdentifier("https://idp.example.com/123456789/", "e9297990-14d2-42ec-a4a9-4036db86509a")
+	id, err := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: user, Device: device})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	b, err := json.Marshal(id)
+	if err != nil {
+		t.Errorf("MarshalJSON() error = %v", err)
+		return
+	}
+	got := string(b)
+	want := `{"user":{"format":"email","email":"user@example.com"},"device":{"format":"iss_sub","iss":"https://idp.example.com/123456789/","sub":"e9297990-14d2-42ec-a4a9-4036db86509a"}}`
+	if got != want {
+		t.Errorf("MarshalJSON() got = %v, want %v", got, want)
+	}
+
+	decoded, err := secevsubid.DecodeJSON(b)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(decoded, id) {
+		t.Errorf("DecodeJSON() got = %v, want %v", decoded, id)
+	}
+}
+
+func TestDecodeJSONWithComplex(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{
+			name:    "complex success",
+			json:    `{"user":{"format":"email","email":"user@example.com"},"tenant":{"format":"opaque","id":"123456"}}`,
+			wantErr: false,
+		},
+		{
+			name:    "complex with format member",
+			json:    `{"format":"complex","user":{"format":"email","email":"user@example.com"},"session":{"format":"opaque","id":"dMTlD|1600802906337.16|16008.16"}}`,
+			wantErr: false,
+		},
+		{
+			name:    "nested complex",
+			json:    `{"user":{"format":"email","email":"user@example.com"},"group":{"user":{"format":"opaque","id":"123456"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "nested complex with format member",
+			json:    `{"format":"complex","user":{"format":"email","email":"user@example.com"},"group":{"format":"complex","user":{"format":"opaque","id":"123456"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "complex with format member but no members",
+			json:    `{"format":"complex"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid member",
+			json:    `{"user":{"format":"email"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON object member",
+			json:    `{"user":"user@example.com"}`,
+			wantErr: true,
+		},
+		{
+			name:    "complex in aliases",
+			json:    `{"format":"aliases","identifiers":[{"user":{"format":"email","email":"user@example.com"}}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := secevsubid.DecodeJSON([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchSubject(t *testing.T) {
+	user, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	other, _ := secevsubid.NewEmailIdentifier("other@example.com")
+	session, _ := secevsubid.NewOpaqueIdentifier("session-1")
+	aliases, _ := secevsubid.NewAliasesIdentifier(other, user)
+	userOnly, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: user})
+	userSession, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: user, Session: session})
+	otherSession, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: other, Session: session})
+
+	tests := []struct {
+		name    string
+		pattern secevsubid.SubjectIdentifier
+		subject secevsubid.SubjectIdentifier
+		want    bool
+	}{
+		{
+			name:    "same simple",
+			pattern: user,
+			subject: user,
+			want:    true,
+		},
+		{
+			name:    "different simple",
+			pattern: user,
+			subject: other,
+			want:    false,
+		},
+		{
+			name:    "complex pattern subset",
+			pattern: userOnly,
+			subject: userSession,
+			want:    true,
+		},
+		{
+			name:    "complex pattern superset",
+			pattern: userSession,
+			subject: userOnly,
+			want:    false,
+		},
+		{
+			name:    "complex pattern different member",
+			pattern: userSession,
+			subject: otherSession,
+			want:    false,
+		},
+		{
+			name:    "simple pattern complex subject",
+			pattern: session,
+			subject: userSession,
+			want:    true,
+		},
+		{
+			name:    "aliases pattern",
+			pattern: aliases,
+			subject: user,
+			want:    true,
+		},
+		{
+			name:    "aliases subject",
+			pattern: user,
+			subject: aliases,
+			want:    true,
+		},
+		{
+			name:    "complex pattern simple subject",
+			pattern: userOnly,
+			subject: user,
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secevsubid.MatchSubject(tt.pattern, tt.subject); got != tt.want {
+				t.Errorf("MatchSubject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONWithComplexFormatMember(t *testing.T) {
+	user, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	session, _ := secevsubid.NewOpaqueIdentifier("dMTlD|1600802906337.16|16008.16")
+	want, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: user, Session: session})
+
+	for _, s := range []string{
+		`{"user":{"format":"email","email":"user@example.com"},"session":{"format":"opaque","id":"dMTlD|1600802906337.16|16008.16"}}`,
+		`{"format":"complex","user":{"format":"email","email":"user@example.com"},"session":{"format":"opaque","id":"dMTlD|1600802906337.16|16008.16"}}`,
+	} {
+		got, err := secevsubid.DecodeJSON([]byte(s))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeJSON(%s) = %v, want %v", s, got, want)
+		}
+	}
+
+	got, err := secevsubid.DecodeXML([]byte(`<subject format="complex"><user format="email"><email>user@example.com</email></user></subject>`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Format() != secevsubid.FormatComplex {
+		t.Errorf("DecodeXML() format = %s, want %s", got.Format(), secevsubid.FormatComplex)
+	}
+}
@@ -21,6 +21,13 @@ const (
 	FormatUri = Format("uri")
 	// FormatAliases is the format name for Aliases Identifier Format.
 	FormatAliases = Format("aliases")
+	// FormatJwtId is the format name for JWT ID Subject Identifier Format of OpenID Shared Signals Framework.
+	FormatJwtId = Format("jwt_id")
+	// FormatSamlAssertionId is the format name for SAML Assertion ID Subject Identifier Format of OpenID Shared Signals Framework.
+	FormatSamlAssertionId = Format("saml_assertion_id")
+	// FormatComplex is the format name for Complex Subject of OpenID Shared Signals Framework.
+	// This value is not written to JSON because Complex Subject has no "format" field.
+	FormatComplex = Format("complex")
 
 	// FieldFormat is the field name for "format" field.
 	// This field is used in all identifier format.
@@ -49,6 +56,29 @@ const (
 	// FieldIdentifiers is the field name for "identifiers" field.
 	// This field is used in Aliases Identifier Format
 	fieldIdentifiers = "identifiers"
+	// FieldJwtId is the field name for "jti" field.
+	// This field is used in JWT ID Subject Identifier Format.
+	fieldJwtId = "jti"
+	// FieldSamlIssuer is the field name for "issuer" field.
+	// This field is used in SAML Assertion ID Subject Identifier Format.
+	fieldSamlIssuer = "issuer"
+	// FieldAssertionId is the field name for "assertion_id" field.
+	// This field is used in SAML Assertion ID Subject Identifier Format.
+	fieldAssertionId = "assertion_id"
+	// FieldUser is the field name for "user" member of Complex Subject.
+	fieldUser = "user"
+	// FieldDevice is the field name for "device" member of Complex Subject.
+	fieldDevice = "device"
+	// FieldSession is the field name for "session" member of Complex Subject.
+	fieldSession = "session"
+	// FieldApplication is the field name for "application" member of Complex Subject.
+	fieldApplication = "application"
+	// FieldTenant is the field name for "tenant" member of Complex Subject.
+	fieldTenant = "tenant"
+	// FieldOrgUnit is the field name for "org_unit" member of Complex Subject.
+	fieldOrgUnit = "org_unit"
+	// FieldGroup is the field name for "group" member of Complex Subject.
+	fieldGroup = "group"
 )
 
 var (
@@ -68,10 +98,72 @@ var (
 	ErrEmptyUrl = errors.New("empty url")
 	// ErrNoFormat is error raised when JSON object doesn't have "format" field.
 	ErrNoFormat = errors.New("no format")
+	// ErrNoSubjectType is error raised when legacy JSON object doesn't have "subject_type" field.
+	ErrNoSubjectType = errors.New("no subject_type")
+	// ErrNoLegacySubjectType is error raised when the format has no corresponding legacy subject type.
+	ErrNoLegacySubjectType = errors.New("no legacy subject_type")
 	// ErrNestedAliases is error raised  when identifiers in Aliases Identifier Format include Aliases Identifier Format.
 	ErrNestedAliases = errors.New("nested aliases")
 	// ErrDuplicatedIdentifier is error raised when duplicate identifiers exist in identifiers field.
 	ErrDuplicatedIdentifier = errors.New("identifier duplicated")
 	// ErrEmptyIdentifiers is error raised when identifiers value does not exist at generation time.
 	ErrEmptyIdentifiers = errors.New("empty identifiers")
+	// ErrEmptyComplex is error raised when Complex Subject has no members at generation time.
+	ErrEmptyComplex = errors.New("empty complex")
+	// ErrNestedComplex is error raised when Complex Subject is used as a member of Complex Subject or Aliases Identifier Format.
+	ErrNestedComplex = errors.New("nested complex")
+	// ErrEmptyJwtId is error raised when jti value does not exist at generation time.
+	ErrEmptyJwtId = errors.New("empty jti")
+	// ErrEmptyAssertionId is error raised when assertion_id value does not exist at generation time.
+	ErrEmptyAssertionId = errors.New("empty assertion_id")
+	// ErrEmptyEvents is error raised when Security Event Token has no events.
+	ErrEmptyEvents = errors.New("empty events")
+	// ErrNoSubject is error raised when Security Event Token has no subject for validating events.
+	ErrNoSubject = errors.New("no subject")
+	// ErrSubjectFormatNotAllowed is error raised when the format of the subject is not allowed by the event.
+	ErrSubjectFormatNotAllowed = errors.New("subject format not allowed")
+	// ErrMissingEventMember is error raised when the required member of the event does not exist.
+	ErrMissingEventMember = errors.New("missing event member")
+	// ErrInvalidEventMember is error raised when the member of the event has an unexpected value.
+	ErrInvalidEventMember = errors.New("invalid event member")
+	// ErrEmptyStreamId is error raised when stream_id value does not exist.
+	ErrEmptyStreamId = errors.New("empty stream_id")
+	// ErrInvalidStreamStatus is error raised when status of stream is not one of enabled, paused and disabled.
+	ErrInvalidStreamStatus = errors.New("invalid stream status")
+	// ErrStreamNotFound is error raised when the stream does not exist.
+	ErrStreamNotFound = errors.New("stream not found")
+	// ErrStreamExists is error raised when the stream with the same stream_id already exists.
+	ErrStreamExists = errors.New("stream already exists")
+	// ErrSubjectNotFound is error raised when the subject does not exist in the stream.
+	ErrSubjectNotFound = errors.New("subject not found")
+	// ErrNoEndpoint is error raised when the transmitter does not provide the endpoint.
+	ErrNoEndpoint = errors.New("no endpoint")
+	// ErrNotStreamEvent is error raised when Security Event Token does not hold a single verification or stream updated event.
+	ErrNotStreamEvent = errors.New("not stream event")
+	// ErrUnknownVerificationState is error raised when the state of verification event is not requested or expired.
+	ErrUnknownVerificationState = errors.New("unknown verification state")
+	// ErrMalformedSET is error raised when Security Event Token is not JWS compact serialization.
+	ErrMalformedSET = errors.New("malformed security event token")
+	// ErrInvalidSignature is error raised when the signature of Security Event Token does not match.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrReplayedSET is error raised when Security Event Token with the same iss and jti has already been received.
+	ErrReplayedSET = errors.New("replayed security event token")
+	// ErrUnresolvedSubject is error raised when the subject is not mapped to any account.
+	ErrUnresolvedSubject = errors.New("unresolved subject")
+	// ErrResolveConflict is error raised when the subject is mapped to different accounts.
+	ErrResolveConflict = errors.New("resolve conflict")
+	// ErrNoAcceptableSubject is error raised when no identifier is acceptable by the peer.
+	ErrNoAcceptableSubject = errors.New("no acceptable subject")
+	// ErrInvalidKey is error raised when the key or its key ID is empty or malformed.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrUnknownKeyId is error raised when the key ID is not registered in the key ring.
+	ErrUnknownKeyId = errors.New("unknown key id")
+	// ErrInvalidCiphertext is error raised when the sealed value is malformed or fails authentication.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrMalformedCBOR is error raised when the CBOR data item is not well-formed or not supported as identifier.
+	ErrMalformedCBOR = errors.New("malformed cbor")
+	// ErrTransientNameID is error raised when SAML NameID is transient, which must not be used to identify the subject later.
+	ErrTransientNameID = errors.New("transient name id")
+	// ErrUnsupportedNameIDFormat is error raised when SAML NameID format has no corresponding identifier format.
+	ErrUnsupportedNameIDFormat = errors.New("unsupported name id format")
 )
@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // DidIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Decentralized Identifier (DID) Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-decentralized-identifier-di
@@ -34,6 +39,32 @@ func (id *didIdentifier) Validate() error {
 	return nil
 }
 
+func (id *didIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *didIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *didIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *didIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "didIdentifier")
+}
+
+func (id *didIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatDid)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*didIdentifier)
+	return nil
+}
+
 // NewDidIdentifier creates new instance of DidIdentifier.
 // The argument "url" is required. If it's empty, this function returns error.
 func NewDidIdentifier(url string) (DidIdentifier, error) {
@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // EmailIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Email Identifier Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-email-identifier-format
@@ -34,6 +39,32 @@ func (id *emailIdentifier) Validate() error {
 	return nil
 }
 
+func (id *emailIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *emailIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *emailIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *emailIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "emailIdentifier")
+}
+
+func (id *emailIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatEmail)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*emailIdentifier)
+	return nil
+}
+
 // NewEmailIdentifier creates new instance of EmailIdentifier.
 // The argument "email" is required. If it's empty, this function returns error.
 func NewEmailIdentifier(email string) (EmailIdentifier, error) {
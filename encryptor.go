@@ -0,0 +1,170 @@
+package secevsubid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Encryptor seals JSON representation of SubjectIdentifier with AES-GCM for storing identifiers encrypted at rest.
+// The sealed value is "<key ID>.<base64url encoded nonce and ciphertext>" and the key ID is authenticated as additional data.
+// Keys are held in a key ring, so that values sealed before key rotation can be opened and re-encrypted.
+// The zero value has no keys, so call Rotate before use.
+type Encryptor struct {
+	mu      sync.RWMutex
+	keys    map[string]cipher.AEAD
+	current string
+}
+
+// NewEncryptor creates new instance of Encryptor using the key as the current key.
+// The key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewEncryptor(kid string, key []byte) (*Encryptor, error) {
+	e := &Encryptor{}
+	if err := e.Rotate(kid, key); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// AddKey registers the key for opening values sealed with it. The current key is not changed.
+// The key ID must not be empty and must not contain ".".
+func (e *Encryptor) AddKey(kid string, key []byte) error {
+	if kid == "" || strings.Contains(kid, ".") {
+		return fmt.Errorf("%w: kid = %q", ErrInvalidKey, kid)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.keys == nil {
+		e.keys = make(map[string]cipher.AEAD)
+	}
+	e.keys[kid] = aead
+	return nil
+}
+
+// Rotate registers the key and uses it as the current key.
+func (e *Encryptor) Rotate(kid string, key []byte) error {
+	if err := e.AddKey(kid, key); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.current = kid
+	return nil
+}
+
+// KeyId returns the ID of the current key.
+func (e *Encryptor) KeyId() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.current
+}
+
+// Seal validates the identifier and encrypts its JSON representation with the current key.
+func (e *Encryptor) Seal(id SubjectIdentifier) (string, error) {
+	if id == nil {
+		return "", ErrNoSubject
+	}
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+
+	kid := e.KeyId()
+	aead, err := e.aead(kid)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(b)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, b, []byte(kid))
+	return kid + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts the sealed value and decodes it via DecodeJSON.
+func (e *Encryptor) Open(sealed string) (SubjectIdentifier, error) {
+	kid, b, err := e.open(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := DecodeJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", kid, err)
+	}
+	return id, nil
+}
+
+// Reseal re-encrypts the sealed value with the current key for key rotation.
+// If the value is already sealed with the current key, it is returned as it is.
+func (e *Encryptor) Reseal(sealed string) (string, error) {
+	if kid, _, ok := strings.Cut(sealed, "."); ok && kid == e.KeyId() {
+		if _, _, err := e.open(sealed); err != nil {
+			return "", err
+		}
+		return sealed, nil
+	}
+
+	id, err := e.Open(sealed)
+	if err != nil {
+		return "", err
+	}
+	return e.Seal(id)
+}
+
+func (e *Encryptor) open(sealed string) (string, []byte, error) {
+	kid, encoded, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return "", nil, ErrInvalidCiphertext
+	}
+
+	aead, err := e.aead(kid)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(b) < aead.NonceSize() {
+		return "", nil, ErrInvalidCiphertext
+	}
+
+	plain, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(kid))
+	if err != nil {
+		return "", nil, ErrInvalidCiphertext
+	}
+	return kid, plain, nil
+}
+
+func (e *Encryptor) aead(kid string) (cipher.AEAD, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	aead, ok := e.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyId, kid)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,144 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	testKey1 = []byte("0123456789abcdef0123456789abcdef")
+	testKey2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestEncryptor_SealAndOpen(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: email})
+
+	e, err := secevsubid.NewEncryptor("k1", testKey1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name string
+		id   secevsubid.SubjectIdentifier
+	}{
+		{name: "email", id: email},
+		{name: "iss_sub", id: issSub},
+		{name: "aliases", id: aliases},
+		{name: "complex", id: complexSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := e.Seal(tt.id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !strings.HasPrefix(sealed, "k1.") || strings.Contains(sealed, "example") {
+				t.Errorf("Seal() = %v, want k1 prefixed ciphertext", sealed)
+			}
+
+			got, err := e.Open(sealed)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.id) {
+				t.Errorf("Open() = %v, want %v", got, tt.id)
+			}
+		})
+	}
+}
+
+func TestEncryptor_OpenWithError(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	e, _ := secevsubid.NewEncryptor("k1", testKey1)
+	sealed, _ := e.Seal(email)
+	_, encoded, _ := strings.Cut(sealed, ".")
+
+	tests := []struct {
+		name    string
+		sealed  string
+		wantErr error
+	}{
+		{name: "no key id", sealed: encoded, wantErr: secevsubid.ErrInvalidCiphertext},
+		{name: "unknown key id", sealed: "k9." + encoded, wantErr: secevsubid.ErrUnknownKeyId},
+		{name: "tampered", sealed: sealed[:len(sealed)-2] + "AA", wantErr: secevsubid.ErrInvalidCiphertext},
+		{name: "short", sealed: "k1.AAAA", wantErr: secevsubid.ErrInvalidCiphertext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := e.Open(tt.sealed); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := secevsubid.NewEncryptor("k1", []byte("short")); !errors.Is(err, secevsubid.ErrInvalidKey) {
+		t.Errorf("NewEncryptor() error = %v, wantErr %v", err, secevsubid.ErrInvalidKey)
+	}
+}
+
+func TestEncryptor_Reseal(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	e, _ := secevsubid.NewEncryptor("k1", testKey1)
+	old, _ := e.Seal(email)
+
+	if err := e.Rotate("k2", testKey2); err != nil {
+		t.Error(err)
+		return
+	}
+	resealed, err := e.Reseal(old)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.HasPrefix(resealed, "k2.") {
+		t.Errorf("Reseal() = %v, want k2 prefixed", resealed)
+	}
+	if again, _ := e.Reseal(resealed); again != resealed {
+		t.Errorf("Reseal() = %v, want unchanged %v", again, resealed)
+	}
+
+	// The key ID is authenticated, so ciphertext cannot be moved to another key ID.
+	_, encoded, _ := strings.Cut(old, ".")
+	if _, err = e.Open("k2." + encoded); !errors.Is(err, secevsubid.ErrInvalidCiphertext) {
+		t.Errorf("Open() error = %v, wantErr %v", err, secevsubid.ErrInvalidCiphertext)
+	}
+	got, err := e.Open(resealed)
+	if err != nil || !reflect.DeepEqual(got, email) {
+		t.Errorf("Open() = %v, %v, want %v", got, err, email)
+	}
+}
+
+func TestEncryptor_ZeroValue(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	e := &secevsubid.Encryptor{}
+	if _, err := e.Seal(email); !errors.Is(err, secevsubid.ErrUnknownKeyId) {
+		t.Errorf("Seal() error = %v, wantErr %v", err, secevsubid.ErrUnknownKeyId)
+	}
+	if err := e.Rotate("k1", testKey1); err != nil {
+		t.Error(err)
+		return
+	}
+	sealed, err := e.Seal(email)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := e.Open(sealed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got, email) {
+		t.Errorf("Open() = %v, want %v", got, email)
+	}
+}
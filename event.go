@@ -0,0 +1,193 @@
+package secevsubid
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Event is interface for handling typed payload of each event held in "events" claim of Security Event Token.
+type Event interface {
+	// EventType returns event type URI used as the key of "events" claim.
+	EventType() string
+	// SubjectFormats returns formats allowed as the subject of the event.
+	// If it returns nil, any format is allowed.
+	SubjectFormats() []Format
+	// Validate values held and returns an error if there is a problem.
+	Validate() error
+}
+
+// RawEvent is Event whose event type is not known by this package.
+// The payload is held as it is.
+type RawEvent struct {
+	// Type is event type URI.
+	Type string
+	// Payload is JSON representation of the event.
+	Payload json.RawMessage
+}
+
+// EventType implements Event.
+func (e *RawEvent) EventType() string {
+	return e.Type
+}
+
+// SubjectFormats implements Event.
+func (e *RawEvent) SubjectFormats() []Format {
+	return nil
+}
+
+// Validate implements Event.
+func (e *RawEvent) Validate() error {
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *RawEvent) MarshalJSON() ([]byte, error) {
+	if len(e.Payload) == 0 {
+		return []byte("{}"), nil
+	}
+	return e.Payload, nil
+}
+
+var eventFactories = map[string]func() Event{
+	EventTypeSessionRevoked:         func() Event { return &SessionRevokedEvent{} },
+	EventTypeTokenClaimsChange:      func() Event { return &TokenClaimsChangeEvent{} },
+	EventTypeCredentialChange:       func() Event { return &CredentialChangeEvent{} },
+	EventTypeAssuranceLevelChange:   func() Event { return &AssuranceLevelChangeEvent{} },
+	EventTypeDeviceComplianceChange: func() Event { return &DeviceComplianceChangeEvent{} },
+
+	EventTypeAccountCredentialChangeRequired: func() Event { return &AccountCredentialChangeRequiredEvent{} },
+	EventTypeAccountPurged:                   func() Event { return &AccountPurgedEvent{} },
+	EventTypeAccountDisabled:                 func() Event { return &AccountDisabledEvent{} },
+	EventTypeAccountEnabled:                  func() Event { return &AccountEnabledEvent{} },
+	EventTypeIdentifierChanged:               func() Event { return &IdentifierChangedEvent{} },
+	EventTypeIdentifierRecycled:              func() Event { return &IdentifierRecycledEvent{} },
+	EventTypeCredentialCompromise:            func() Event { return &CredentialCompromiseEvent{} },
+	EventTypeOptIn:                           func() Event { return &OptInEvent{} },
+	EventTypeOptOutInitiated:                 func() Event { return &OptOutInitiatedEvent{} },
+	EventTypeOptOutCancelled:                 func() Event { return &OptOutCancelledEvent{} },
+	EventTypeOptOutEffective:                 func() Event { return &OptOutEffectiveEvent{} },
+	EventTypeRecoveryActivated:               func() Event { return &RecoveryActivatedEvent{} },
+	EventTypeRecoveryInformationChanged:      func() Event { return &RecoveryInformationChangedEvent{} },
+
+	EventTypeVerification:  func() Event { return &VerificationEvent{} },
+	EventTypeStreamUpdated: func() Event { return &StreamUpdatedEvent{} },
+}
+
+// DecodeEvents decodes each event held in "events" claim to typed Event and validates it.
+// Events whose type is not known are decoded to RawEvent.
+// Returned events are sorted by event type URI.
+func DecodeEvents(events map[string]json.RawMessage) ([]Event, error) {
+	types := make([]string, 0, len(events))
+	for t := range events {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	es := make([]Event, 0, len(events))
+	for _, t := range types {
+		f, ok := eventFactories[t]
+		if !ok {
+			es = append(es, &RawEvent{Type: t, Payload: events[t]})
+			continue
+		}
+
+		e := f()
+		if err := json.Unmarshal(events[t], e); err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		if err := e.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// EncodeEvents validates events and encodes them to the value of "events" claim.
+func EncodeEvents(events ...Event) (map[string]json.RawMessage, error) {
+	m := make(map[string]json.RawMessage, len(events))
+	for _, e := range events {
+		if err := e.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.EventType(), err)
+		}
+
+		b, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		m[e.EventType()] = b
+	}
+
+	return m, nil
+}
+
+// ValidateEventSubject returns an error if the subject is not allowed by the event.
+// AliasesIdentifier is allowed when any of its identifiers is allowed.
+func ValidateEventSubject(e Event, subject SubjectIdentifier) error {
+	if subject == nil {
+		return ErrNoSubject
+	}
+
+	formats := e.SubjectFormats()
+	if formats == nil || containsFormat(formats, subject.Format()) {
+		return nil
+	}
+
+	if aliases, ok := subject.(AliasesIdentifier); ok {
+		for _, id := range aliases.Identifiers() {
+			if containsFormat(formats, id.Format()) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not allowed for %s", ErrSubjectFormatNotAllowed, subject.Format(), e.EventType())
+}
+
+func containsFormat(formats []Format, f Format) bool {
+	for _, v := range formats {
+		if v == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddEvent validates the event and adds it to "events" claim held by the instance.
+func (set *SecurityEventToken) AddEvent(e Event) error {
+	m, err := EncodeEvents(e)
+	if err != nil {
+		return err
+	}
+
+	if set.Events == nil {
+		set.Events = make(map[string]json.RawMessage)
+	}
+	set.Events[e.EventType()] = m[e.EventType()]
+	return nil
+}
+
+// DecodeEvents decodes "events" claim held by the instance to typed Events.
+// See DecodeEvents function for details.
+func (set *SecurityEventToken) DecodeEvents() ([]Event, error) {
+	return DecodeEvents(set.Events)
+}
+
+// ValidateEvents decodes "events" claim held by the instance and validates "sub_id" claim against each event.
+func (set *SecurityEventToken) ValidateEvents() ([]Event, error) {
+	es, err := set.DecodeEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range es {
+		if err = ValidateEventSubject(e, set.Subject()); err != nil {
+			return nil, err
+		}
+	}
+
+	return es, nil
+}
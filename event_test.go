@@ -0,0 +1,146 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeEvents(t *testing.T) {
+	events := map[string]json.RawMessage{
+		secevsubid.EventTypeSessionRevoked:      json.RawMessage(`{"event_timestamp":1615304991643,"initiating_entity":"policy"}`),
+		"https://example.com/event-type/custom": json.RawMessage(`{"foo":"bar"}`),
+	}
+
+	got, err := secevsubid.DecodeEvents(events)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []secevsubid.Event{
+		&secevsubid.RawEvent{Type: "https://example.com/event-type/custom", Payload: json.RawMessage(`{"foo":"bar"}`)},
+		&secevsubid.SessionRevokedEvent{CAEPEventMetadata: secevsubid.CAEPEventMetadata{EventTimestamp: 1615304991643, InitiatingEntity: secevsubid.InitiatingEntityPolicy}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeEvents() got = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeEventsWithInvalidEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  map[string]json.RawMessage
+		wantErr error
+	}{
+		{
+			name:    "missing member",
+			events:  map[string]json.RawMessage{secevsubid.EventTypeTokenClaimsChange: json.RawMessage(`{}`)},
+			wantErr: secevsubid.ErrMissingEventMember,
+		},
+		{
+			name:    "invalid member",
+			events:  map[string]json.RawMessage{secevsubid.EventTypeSessionRevoked: json.RawMessage(`{"event_timestamp":1615304991643,"initiating_entity":"robot"}`)},
+			wantErr: secevsubid.ErrInvalidEventMember,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := secevsubid.DecodeEvents(tt.events); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodeEvents(t *testing.T) {
+	e := &secevsubid.SessionRevokedEvent{CAEPEventMetadata: secevsubid.CAEPEventMetadata{EventTimestamp: 1615304991643}}
+	got, err := secevsubid.EncodeEvents(e)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := `{"event_timestamp":1615304991643}`
+	if string(got[secevsubid.EventTypeSessionRevoked]) != want {
+		t.Errorf("EncodeEvents() got = %s, want %s", got[secevsubid.EventTypeSessionRevoked], want)
+	}
+
+	if _, err = secevsubid.EncodeEvents(&secevsubid.TokenClaimsChangeEvent{}); err == nil {
+		t.Error("error should be raised when event is invalid")
+	}
+}
+
+func TestValidateEventSubject(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	did, _ := secevsubid.NewDidIdentifier("did:example:123456")
+	jwtId, _ := secevsubid.NewJwtIdIdentifier("https://idp.example.com/", "jti")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, jwtId)
+	e := &secevsubid.TokenClaimsChangeEvent{}
+
+	tests := []struct {
+		name    string
+		event   secevsubid.Event
+		subject secevsubid.SubjectIdentifier
+		wantErr error
+	}{
+		{
+			name:    "allowed",
+			event:   e,
+			subject: jwtId,
+			wantErr: nil,
+		},
+		{
+			name:    "not allowed",
+			event:   e,
+			subject: did,
+			wantErr: secevsubid.ErrSubjectFormatNotAllowed,
+		},
+		{
+			name:    "aliases including allowed",
+			event:   e,
+			subject: aliases,
+			wantErr: nil,
+		},
+		{
+			name:    "no subject",
+			event:   e,
+			subject: nil,
+			wantErr: secevsubid.ErrNoSubject,
+		},
+		{
+			name:    "raw event",
+			event:   &secevsubid.RawEvent{Type: "https://example.com/event-type/custom"},
+			subject: did,
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := secevsubid.ValidateEventSubject(tt.event, tt.subject); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateEventSubject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecurityEventToken_ValidateEvents(t *testing.T) {
+	set := newTestSET(t, "jti")
+	set.Events = map[string]json.RawMessage{
+		secevsubid.EventTypeDeviceComplianceChange: json.RawMessage(`{"event_timestamp":1615304991643,"previous_status":"compliant","current_status":"not-compliant"}`),
+	}
+	if _, err := set.ValidateEvents(); !errors.Is(err, secevsubid.ErrSubjectFormatNotAllowed) {
+		t.Errorf("ValidateEvents() error = %v, wantErr %v", err, secevsubid.ErrSubjectFormatNotAllowed)
+	}
+
+	device, _ := secevsubid.NewIssuerSubjectIdentifier("https://idp.example.com/", "e9297990-14d2-42ec-a4a9-4036db86509a")
+	set.SubId = secevsubid.NewWrapper(device)
+	es, err := set.ValidateEvents()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(es) != 1 || es[0].EventType() != secevsubid.EventTypeDeviceComplianceChange {
+		t.Errorf("ValidateEvents() got = %v, want device compliance change event", es)
+	}
+}
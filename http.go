@@ -0,0 +1,85 @@
+package secevsubid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// StatusError is error returned by clients of this package when the server responds with non-2xx status.
+type StatusError struct {
+	// StatusCode is HTTP status code of the response.
+	StatusCode int `json:"-"`
+	// Err is the error code in the response body if exists.
+	Err string `json:"err"`
+	// Description is the error description in the response body if exists.
+	Description string `json:"description"`
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	if e.Err == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status %d: %s: %s", e.StatusCode, e.Err, e.Description)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, code string, description string) {
+	writeJSON(w, status, &SETError{Err: code, Description: description})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, in interface{}, out interface{}) error {
+	return doJSON(ctx, client, http.MethodPost, url, header, in, out)
+}
+
+func doJSON(ctx context.Context, client *http.Client, method string, url string, header http.Header, in interface{}, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, &body)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		e := &StatusError{StatusCode: res.StatusCode}
+		_ = json.NewDecoder(res.Body).Decode(e)
+		return e
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
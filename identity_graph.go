@@ -0,0 +1,238 @@
+package secevsubid
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// IdentityGraph accumulates knowledge that identifiers belong to the same subject.
+// Identifiers are grouped by union-find over their canonical form, and members of AliasesIdentifier are joined into a group.
+// ComplexIdentifier is treated as its "user" member.
+// The zero value is an empty graph ready to use.
+type IdentityGraph struct {
+	mu      sync.Mutex
+	parent  map[string]string
+	size    map[string]int
+	members map[string]SubjectIdentifier
+}
+
+// NewIdentityGraph creates new instance of IdentityGraph.
+func NewIdentityGraph() *IdentityGraph {
+	return &IdentityGraph{
+		parent:  make(map[string]string),
+		size:    make(map[string]int),
+		members: make(map[string]SubjectIdentifier),
+	}
+}
+
+// Add records the subject. For AliasesIdentifier, all members are joined into a group.
+// It returns true if the subject joined two or more groups which were previously known as separate subjects.
+func (g *IdentityGraph) Add(subject SubjectIdentifier) (bool, error) {
+	keys, err := resolvableKeys(subject)
+	if err != nil {
+		return false, err
+	}
+
+	ids := resolvableIdentifiers(subject)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	known := make(map[string]bool)
+	for i, k := range keys {
+		if _, ok := g.parent[k]; ok {
+			known[g.find(k)] = true
+		}
+		g.add(k, ids[i])
+	}
+
+	for _, k := range keys[1:] {
+		g.union(keys[0], k)
+	}
+
+	return len(known) > 1, nil
+}
+
+// Identifiers returns all identifiers known to belong to the same subject as the argument, sorted by canonical form.
+// For AliasesIdentifier, identifiers of all groups its members belong to are returned.
+// If the subject is unknown, it returns nil.
+func (g *IdentityGraph) Identifiers(subject SubjectIdentifier) []SubjectIdentifier {
+	keys, err := resolvableKeys(subject)
+	if err != nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	roots := make(map[string]bool)
+	for _, k := range keys {
+		if _, ok := g.parent[k]; ok {
+			roots[g.find(k)] = true
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+
+	var found []string
+	for k := range g.parent {
+		if roots[g.find(k)] {
+			found = append(found, k)
+		}
+	}
+	sort.Strings(found)
+
+	ids := make([]SubjectIdentifier, len(found))
+	for i, k := range found {
+		ids[i] = g.members[k]
+	}
+	return ids
+}
+
+// Same returns whether both subjects are known to belong to the same subject.
+func (g *IdentityGraph) Same(a SubjectIdentifier, b SubjectIdentifier) bool {
+	ak, err := resolvableKeys(a)
+	if err != nil {
+		return false
+	}
+	bk, err := resolvableKeys(b)
+	if err != nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, x := range ak {
+		if _, ok := g.parent[x]; !ok {
+			continue
+		}
+		for _, y := range bk {
+			if _, ok := g.parent[y]; ok && g.find(x) == g.find(y) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Groups returns identifiers grouped by subject.
+// Each group is sorted by canonical form, and groups are sorted by their first identifier.
+func (g *IdentityGraph) Groups() [][]SubjectIdentifier {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	byRoot := make(map[string][]string)
+	for k := range g.parent {
+		r := g.find(k)
+		byRoot[r] = append(byRoot[r], k)
+	}
+
+	groups := make([][]string, 0, len(byRoot))
+	for _, ks := range byRoot {
+		sort.Strings(ks)
+		groups = append(groups, ks)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+
+	ids := make([][]SubjectIdentifier, len(groups))
+	for i, ks := range groups {
+		ids[i] = make([]SubjectIdentifier, len(ks))
+		for j, k := range ks {
+			ids[i][j] = g.members[k]
+		}
+	}
+	return ids
+}
+
+type identityGraphJSON struct {
+	Groups [][]*Wrapper `json:"groups"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// The state is persisted as the list of groups returned by Groups.
+func (g *IdentityGraph) MarshalJSON() ([]byte, error) {
+	groups := g.Groups()
+	v := identityGraphJSON{Groups: make([][]*Wrapper, len(groups))}
+	for i, ids := range groups {
+		v.Groups[i] = make([]*Wrapper, len(ids))
+		for j, id := range ids {
+			v.Groups[i][j] = NewWrapper(id)
+		}
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The state restored is added to the current state.
+func (g *IdentityGraph) UnmarshalJSON(b []byte) error {
+	var v identityGraphJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, ws := range v.Groups {
+		var first string
+		for i, w := range ws {
+			if w == nil || w.Value() == nil {
+				return ErrNoSubject
+			}
+			k, err := identifierKey(w.Value())
+			if err != nil {
+				return err
+			}
+			g.add(k, w.Value())
+			if i == 0 {
+				first = k
+			} else {
+				g.union(first, k)
+			}
+		}
+	}
+
+	return nil
+}
+
+// add records the identifier as a group of its own if it is unknown. The caller must hold g.mu.
+func (g *IdentityGraph) add(k string, id SubjectIdentifier) {
+	if g.parent == nil {
+		g.parent = make(map[string]string)
+		g.size = make(map[string]int)
+		g.members = make(map[string]SubjectIdentifier)
+	}
+	if _, ok := g.parent[k]; ok {
+		return
+	}
+
+	g.parent[k] = k
+	g.size[k] = 1
+	g.members[k] = id
+}
+
+func (g *IdentityGraph) find(k string) string {
+	for g.parent[k] != k {
+		g.parent[k] = g.parent[g.parent[k]]
+		k = g.parent[k]
+	}
+
+	return k
+}
+
+func (g *IdentityGraph) union(a string, b string) bool {
+	ra, rb := g.find(a), g.find(b)
+	if ra == rb {
+		return false
+	}
+
+	if g.size[ra] < g.size[rb] {
+		ra, rb = rb, ra
+	}
+	g.parent[rb] = ra
+	g.size[ra] += g.size[rb]
+	delete(g.size, rb)
+	return true
+}
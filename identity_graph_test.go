@@ -0,0 +1,107 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestIdentityGraph(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	upperEmail, _ := secevsubid.NewEmailIdentifier("user@EXAMPLE.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	other, _ := secevsubid.NewOpaqueIdentifier("other")
+	emailAndPhone, _ := secevsubid.NewAliasesIdentifier(email, phone)
+	issSubAndOther, _ := secevsubid.NewAliasesIdentifier(issSub, other)
+	phoneAndIssSub, _ := secevsubid.NewAliasesIdentifier(phone, issSub)
+
+	g := secevsubid.NewIdentityGraph()
+	tests := []struct {
+		name       string
+		subject    secevsubid.SubjectIdentifier
+		wantMerged bool
+	}{
+		{name: "new aliases", subject: emailAndPhone, wantMerged: false},
+		{name: "separate aliases", subject: issSubAndOther, wantMerged: false},
+		{name: "known single", subject: upperEmail, wantMerged: false},
+		{name: "merge", subject: phoneAndIssSub, wantMerged: true},
+		{name: "already merged", subject: phoneAndIssSub, wantMerged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := g.Add(tt.subject)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if merged != tt.wantMerged {
+				t.Errorf("Add() = %v, want %v", merged, tt.wantMerged)
+			}
+		})
+	}
+
+	if got := g.Identifiers(upperEmail); len(got) != 4 {
+		t.Errorf("Identifiers() = %v, want 4 identifiers", got)
+	}
+	if !g.Same(email, other) {
+		t.Errorf("Same() = false, want true")
+	}
+	unknown, _ := secevsubid.NewOpaqueIdentifier("unknown")
+	if got := g.Identifiers(unknown); got != nil {
+		t.Errorf("Identifiers() = %v, want nil", got)
+	}
+	if g.Same(email, unknown) {
+		t.Errorf("Same() = true, want false")
+	}
+}
+
+func TestIdentityGraph_JSON(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	other, _ := secevsubid.NewOpaqueIdentifier("other")
+	emailAndPhone, _ := secevsubid.NewAliasesIdentifier(email, phone)
+
+	g := secevsubid.NewIdentityGraph()
+	_, _ = g.Add(emailAndPhone)
+	_, _ = g.Add(issSub)
+	_, _ = g.Add(other)
+
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	restored := secevsubid.NewIdentityGraph()
+	if err = json.Unmarshal(b, restored); err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(restored.Groups(), g.Groups()) {
+		t.Errorf("Groups() = %v, want %v", restored.Groups(), g.Groups())
+	}
+	if !restored.Same(email, phone) || restored.Same(email, issSub) {
+		t.Errorf("restored groups = %v", restored.Groups())
+	}
+}
+
+func TestIdentityGraph_ZeroValue(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, phone)
+
+	g := &secevsubid.IdentityGraph{}
+	if g.Same(email, phone) || g.Identifiers(email) != nil || len(g.Groups()) != 0 {
+		t.Error("empty graph should know no subject")
+	}
+	if _, err := g.Add(aliases); err != nil {
+		t.Error(err)
+		return
+	}
+	if !g.Same(email, phone) {
+		t.Errorf("Same() = false, want true")
+	}
+}
@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // IssuerSubjectIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Issuer and Subject Identifier Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-issuer-and-subject-identifi
@@ -45,6 +50,32 @@ func (id *issSubIdentifier) Validate() error {
 	return nil
 }
 
+func (id *issSubIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *issSubIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *issSubIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *issSubIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "issSubIdentifier")
+}
+
+func (id *issSubIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatIssuerSubject)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*issSubIdentifier)
+	return nil
+}
+
 // NewIssuerSubjectIdentifier creates new instance of IssuerSubjectIdentifier.
 // The argument "issuer" and "subject" is required. If either one of them is empty, this function returns error.
 func NewIssuerSubjectIdentifier(issuer string, subject string) (IssuerSubjectIdentifier, error) {
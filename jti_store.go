@@ -0,0 +1,358 @@
+package secevsubid
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JTIStore records received Security Event Tokens by "iss" and "jti" claims to detect redelivered ones.
+type JTIStore interface {
+	// Remember records the pair of issuer and jti.
+	// If the pair has already been recorded and not expired, it returns ErrReplayedSET.
+	Remember(ctx context.Context, issuer string, jti string) error
+	// Forget removes the pair of issuer and jti, so that the SET is accepted when it is delivered again.
+	Forget(ctx context.Context, issuer string, jti string) error
+}
+
+// CheckReplay records "iss" and "jti" claims of the SET in the store to reserve it for processing.
+// If the SET has already been received, it returns ErrReplayedSET.
+// If processing the SET fails, call Forget of the store to release the reservation.
+func CheckReplay(ctx context.Context, store JTIStore, set *SecurityEventToken) error {
+	if set.JwtId == "" {
+		return ErrEmptyJwtId
+	}
+
+	if err := store.Remember(ctx, set.Issuer, set.JwtId); err != nil {
+		if errors.Is(err, ErrReplayedSET) {
+			return fmt.Errorf("%w: iss = %s, jti = %s", ErrReplayedSET, set.Issuer, set.JwtId)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// HandleOnce calls the handler for the SET unless the SET has already been recorded in the store.
+// The SET is recorded before the handler is called, so concurrent consumers sharing the store never handle the same SET twice.
+// If the handler fails, the record is removed so that the redelivered SET is handled again.
+// Replayed SETs result in ErrReplayedSET without calling the handler.
+func HandleOnce(ctx context.Context, store JTIStore, set *SecurityEventToken, handler func(ctx context.Context, set *SecurityEventToken) error) error {
+	if err := CheckReplay(ctx, store, set); err != nil {
+		return err
+	}
+
+	if err := handler(ctx, set); err != nil {
+		if ferr := store.Forget(ctx, set.Issuer, set.JwtId); ferr != nil {
+			return errors.Join(err, ferr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+type jtiKey struct {
+	issuer string
+	jti    string
+}
+
+type jtiExpiry struct {
+	key       jtiKey
+	expiresAt time.Time
+}
+
+// jtiExpiryHeap implements heap.Interface ordering pairs by expiry time.
+type jtiExpiryHeap []jtiExpiry
+
+func (h jtiExpiryHeap) Len() int           { return len(h) }
+func (h jtiExpiryHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h jtiExpiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *jtiExpiryHeap) Push(x interface{}) {
+	*h = append(*h, x.(jtiExpiry))
+}
+
+func (h *jtiExpiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// MemoryJTIStore is an in-memory implementation of JTIStore.
+// Recorded pairs expire after TTL and expired pairs are purged in order of expiry.
+// The zero value is a store whose pairs never expire.
+type MemoryJTIStore struct {
+	// TTL is the duration pairs are kept. If zero or less, pairs never expire.
+	TTL time.Duration
+
+	mu       sync.Mutex
+	entries  map[jtiKey]time.Time
+	expiries jtiExpiryHeap
+	now      func() time.Time
+}
+
+// NewMemoryJTIStore creates new instance of MemoryJTIStore.
+func NewMemoryJTIStore(ttl time.Duration) *MemoryJTIStore {
+	return &MemoryJTIStore{
+		TTL:     ttl,
+		entries: make(map[jtiKey]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Remember implements JTIStore.
+func (s *MemoryJTIStore) Remember(ctx context.Context, issuer string, jti string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock()
+	k := jtiKey{issuer: issuer, jti: jti}
+	if s.contains(k, now) {
+		return ErrReplayedSET
+	}
+	s.add(k, s.expiresAt(now))
+	return nil
+}
+
+// Forget implements JTIStore.
+func (s *MemoryJTIStore) Forget(ctx context.Context, issuer string, jti string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, jtiKey{issuer: issuer, jti: jti})
+	return nil
+}
+
+// Len returns the number of recorded pairs which are not expired.
+func (s *MemoryJTIStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(s.clock())
+	return len(s.entries)
+}
+
+func (s *MemoryJTIStore) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+func (s *MemoryJTIStore) expiresAt(now time.Time) time.Time {
+	if s.TTL <= 0 {
+		return time.Time{}
+	}
+	return now.Add(s.TTL)
+}
+
+// contains purges expired pairs and reports whether the pair is recorded. The caller must hold s.mu.
+func (s *MemoryJTIStore) contains(k jtiKey, now time.Time) bool {
+	s.purge(now)
+	_, ok := s.entries[k]
+	return ok
+}
+
+// add records the pair with its expiry time. The caller must hold s.mu.
+func (s *MemoryJTIStore) add(k jtiKey, exp time.Time) {
+	if s.entries == nil {
+		s.entries = make(map[jtiKey]time.Time)
+	}
+	s.entries[k] = exp
+	if !exp.IsZero() {
+		heap.Push(&s.expiries, jtiExpiry{key: k, expiresAt: exp})
+	}
+}
+
+// purge removes pairs expired at now, popping them from the expiry heap. The caller must hold s.mu.
+func (s *MemoryJTIStore) purge(now time.Time) {
+	for len(s.expiries) > 0 && !now.Before(s.expiries[0].expiresAt) {
+		e := heap.Pop(&s.expiries).(jtiExpiry)
+		if exp, ok := s.entries[e.key]; ok && exp.Equal(e.expiresAt) {
+			delete(s.entries, e.key)
+		}
+	}
+}
+
+type jtiRecord struct {
+	Issuer    string     `json:"iss"`
+	JwtId     string     `json:"jti"`
+	ExpiresAt *time.Time `json:"exp,omitempty"`
+	Forgotten bool       `json:"forgotten,omitempty"`
+}
+
+func (r *jtiRecord) key() jtiKey {
+	return jtiKey{issuer: r.Issuer, jti: r.JwtId}
+}
+
+func (r *jtiRecord) expiresAt() time.Time {
+	if r.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return *r.ExpiresAt
+}
+
+// FileJTIStore is an implementation of JTIStore persisting pairs to a file, so that replays are detected across restarts.
+// Each pair is appended to the file as a line of JSON, and Forget appends a line marking the pair forgotten.
+// Expired and forgotten pairs are dropped when the file is opened.
+type FileJTIStore struct {
+	mem  *MemoryJTIStore
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFileJTIStore opens the file at the path, creating it if it does not exist, and loads pairs which are not expired.
+func OpenFileJTIStore(path string, ttl time.Duration) (*FileJTIStore, error) {
+	mem := NewMemoryJTIStore(ttl)
+	records, err := loadJTIRecords(path, mem.clock())
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		if err = writeJTIRecord(f, r); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		mem.add(r.key(), r.expiresAt())
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	f, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileJTIStore{mem: mem, file: f}, nil
+}
+
+func loadJTIRecords(path string, now time.Time) ([]jtiRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	live := make(map[jtiKey]jtiRecord)
+	var order []jtiKey
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var r jtiRecord
+		if err = json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if r.Forgotten {
+			delete(live, r.key())
+			continue
+		}
+		if _, ok := live[r.key()]; !ok {
+			order = append(order, r.key())
+		}
+		live[r.key()] = r
+	}
+
+	var records []jtiRecord
+	for _, k := range order {
+		r, ok := live[k]
+		if !ok {
+			continue
+		}
+		if exp := r.expiresAt(); !exp.IsZero() && !now.Before(exp) {
+			continue
+		}
+		delete(live, k)
+		records = append(records, r)
+	}
+	return records, sc.Err()
+}
+
+func writeJTIRecord(f *os.File, r jtiRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Remember implements JTIStore.
+// The pair is written to the file before it is recorded in memory, so a failed write leaves both unchanged.
+func (s *FileJTIStore) Remember(ctx context.Context, issuer string, jti string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	now := s.mem.clock()
+	k := jtiKey{issuer: issuer, jti: jti}
+	if s.mem.contains(k, now) {
+		return ErrReplayedSET
+	}
+
+	exp := s.mem.expiresAt(now)
+	r := jtiRecord{Issuer: issuer, JwtId: jti}
+	if !exp.IsZero() {
+		r.ExpiresAt = &exp
+	}
+	if err := s.write(r); err != nil {
+		return err
+	}
+
+	s.mem.add(k, exp)
+	return nil
+}
+
+// Forget implements JTIStore.
+func (s *FileJTIStore) Forget(ctx context.Context, issuer string, jti string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.write(jtiRecord{Issuer: issuer, JwtId: jti, Forgotten: true}); err != nil {
+		return err
+	}
+	return s.mem.Forget(ctx, issuer, jti)
+}
+
+func (s *FileJTIStore) write(r jtiRecord) error {
+	if err := writeJTIRecord(s.file, r); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Len returns the number of recorded pairs which are not expired.
+func (s *FileJTIStore) Len() int {
+	return s.mem.Len()
+}
+
+// Close closes the file.
+func (s *FileJTIStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,255 @@
+package secevsubid_test
+
+import (
+	"context"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryJTIStore_Remember(t *testing.T) {
+	ctx := context.Background()
+	s := secevsubid.NewMemoryJTIStore(50 * time.Millisecond)
+	tests := []struct {
+		name    string
+		issuer  string
+		jti     string
+		wantErr error
+	}{
+		{name: "first", issuer: "https://issuer.example.com/", jti: "jti1"},
+		{name: "replayed", issuer: "https://issuer.example.com/", jti: "jti1", wantErr: secevsubid.ErrReplayedSET},
+		{name: "other jti", issuer: "https://issuer.example.com/", jti: "jti2"},
+		{name: "other issuer", issuer: "https://other.example.com/", jti: "jti1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Remember(ctx, tt.issuer, tt.jti); err != tt.wantErr {
+				t.Errorf("Remember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d, want 0 after TTL", s.Len())
+	}
+	if err := s.Remember(ctx, "https://issuer.example.com/", "jti1"); err != nil {
+		t.Errorf("Remember() error = %v after TTL, want nil", err)
+	}
+}
+
+func TestFileJTIStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jti.log")
+	s, err := secevsubid.OpenFileJTIStore(path, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_ = s.Remember(ctx, "https://issuer.example.com/", "jti1")
+	_ = s.Remember(ctx, "https://issuer.example.com/", "jti2")
+	if err = s.Close(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	s, err = secevsubid.OpenFileJTIStore(path, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer s.Close()
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+	if err = s.Remember(ctx, "https://issuer.example.com/", "jti1"); err != secevsubid.ErrReplayedSET {
+		t.Errorf("Remember() error = %v, wantErr %v", err, secevsubid.ErrReplayedSET)
+	}
+	if err = s.Remember(ctx, "https://issuer.example.com/", "jti3"); err != nil {
+		t.Errorf("Remember() error = %v, wantErr nil", err)
+	}
+}
+
+func TestCheckReplay(t *testing.T) {
+	s := secevsubid.NewMemoryJTIStore(time.Hour)
+	set := newTestSET(t, "jti1")
+	if err := secevsubid.CheckReplay(context.Background(), s, set); err != nil {
+		t.Errorf("CheckReplay() error = %v, wantErr nil", err)
+	}
+	if err := secevsubid.CheckReplay(context.Background(), s, set); !errors.Is(err, secevsubid.ErrReplayedSET) {
+		t.Errorf("CheckReplay() error = %v, wantErr %v", err, secevsubid.ErrReplayedSET)
+	}
+}
+
+func TestPollClient_RunWithJTIStore(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	q := secevsubid.NewMemoryEventQueue()
+	token, _ := secevsubid.EncodeSET(newTestSET(t, "duplicated"), key)
+	_ = q.Enqueue("first", token)
+	_ = q.Enqueue("second", token)
+
+	h := secevsubid.NewPollHandler(q)
+	h.Timeout = 50 * time.Millisecond
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	var handled int32
+	c := secevsubid.NewPollClient(srv.URL, key)
+	c.JTIStore = secevsubid.NewMemoryJTIStore(time.Hour)
+	_ = c.Run(ctx, func(ctx context.Context, set *secevsubid.SecurityEventToken) error {
+		atomic.AddInt32(&handled, 1)
+		return nil
+	})
+
+	if handled != 1 {
+		t.Errorf("handled = %d, want 1", handled)
+	}
+	if q.Len() != 0 || len(q.Errors()) != 0 {
+		t.Errorf("Len() = %d, Errors() = %v, want all SETs to be acknowledged", q.Len(), q.Errors())
+	}
+}
+
+func TestPollClient_RunWithJTIStoreAndHandlerError(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	q := &secevsubid.MemoryEventQueue{RedeliveryInterval: 10 * time.Millisecond}
+	token, _ := secevsubid.EncodeSET(newTestSET(t, "retried"), key)
+	_ = q.Enqueue("retried", token)
+
+	h := secevsubid.NewPollHandler(q)
+	h.Timeout = 20 * time.Millisecond
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var handled int32
+	c := secevsubid.NewPollClient(srv.URL, key)
+	c.Interval = 10 * time.Millisecond
+	c.JTIStore = secevsubid.NewMemoryJTIStore(time.Hour)
+	var reported []string
+	c.OnError = func(jti string, err error) {
+		reported = append(reported, jti)
+	}
+	_ = c.Run(ctx, func(ctx context.Context, set *secevsubid.SecurityEventToken) error {
+		if atomic.AddInt32(&handled, 1) == 1 {
+			return errors.New("temporary failure")
+		}
+		cancel()
+		return nil
+	})
+
+	if handled != 2 {
+		t.Errorf("handled = %d, want 2", handled)
+	}
+	if len(reported) != 1 || reported[0] != "retried" {
+		t.Errorf("OnError() called with %v, want handler error of retried", reported)
+	}
+	if q.Len() != 0 || len(q.Errors()) != 0 {
+		t.Errorf("Len() = %d, Errors() = %v, want SET to be acknowledged after retry", q.Len(), q.Errors())
+	}
+}
+
+func TestMemoryJTIStore_ZeroValue(t *testing.T) {
+	ctx := context.Background()
+	s := &secevsubid.MemoryJTIStore{}
+	if err := s.Forget(ctx, "https://issuer.example.com/", "jti1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := s.Remember(ctx, "https://issuer.example.com/", "jti1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := s.Remember(ctx, "https://issuer.example.com/", "jti1"); err != secevsubid.ErrReplayedSET {
+		t.Errorf("Remember() error = %v, wantErr %v", err, secevsubid.ErrReplayedSET)
+	}
+}
+
+func TestFileJTIStore_Forget(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jti.log")
+	s, err := secevsubid.OpenFileJTIStore(path, 1500*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_ = s.Remember(ctx, "https://issuer.example.com/", "jti1")
+	_ = s.Remember(ctx, "https://issuer.example.com/", "jti2")
+	if err = s.Forget(ctx, "https://issuer.example.com/", "jti1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = s.Remember(ctx, "https://issuer.example.com/", "jti1"); err != nil {
+		t.Errorf("Remember() error = %v after Forget, want nil", err)
+	}
+	_ = s.Forget(ctx, "https://issuer.example.com/", "jti2")
+	_ = s.Close()
+
+	s, err = secevsubid.OpenFileJTIStore(path, 1500*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if s.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", s.Len())
+	}
+	_ = s.Close()
+
+	// Expiry is persisted without truncation to seconds.
+	time.Sleep(1600 * time.Millisecond)
+	s, err = secevsubid.OpenFileJTIStore(path, 1500*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer s.Close()
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d, want 0 after TTL", s.Len())
+	}
+}
+
+func TestHandleOnce(t *testing.T) {
+	ctx := context.Background()
+	s := secevsubid.NewMemoryJTIStore(time.Hour)
+	set := newTestSET(t, "jti1")
+	errTemporary := errors.New("temporary failure")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- secevsubid.HandleOnce(ctx, s, set, func(ctx context.Context, set *secevsubid.SecurityEventToken) error {
+			close(started)
+			<-release
+			return errTemporary
+		})
+	}()
+
+	<-started
+	called := false
+	err := secevsubid.HandleOnce(ctx, s, set, func(ctx context.Context, set *secevsubid.SecurityEventToken) error {
+		called = true
+		return nil
+	})
+	if called || !errors.Is(err, secevsubid.ErrReplayedSET) {
+		t.Errorf("HandleOnce() error = %v, called = %v, want replay while the first handler is running", err, called)
+	}
+
+	close(release)
+	if err = <-done; !errors.Is(err, errTemporary) {
+		t.Errorf("HandleOnce() error = %v, wantErr %v", err, errTemporary)
+	}
+	err = secevsubid.HandleOnce(ctx, s, set, func(ctx context.Context, set *secevsubid.SecurityEventToken) error {
+		called = true
+		return nil
+	})
+	if err != nil || !called {
+		t.Errorf("HandleOnce() error = %v, called = %v, want SET to be handled after failure", err, called)
+	}
+}
@@ -0,0 +1,92 @@
+package secevsubid
+
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
+// JwtIdIdentifier is one of the sub-interfaces of SubjectIdentifier.
+// It represents the "JWT ID Subject Identifier Format" defined in the OpenID Shared Signals Framework specification.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-jwt-id-subject-identifier-f
+type JwtIdIdentifier interface {
+	// Format returns name of the format actually held by the instance.
+	// The value is the fixed value "jwt_id".
+	Format() Format
+	// Issuer returns issuer value held by the instance.
+	Issuer() string
+	// JwtId returns jti value held by the instance.
+	JwtId() string
+	// Validate values held and returns an error if there is a problem.
+	Validate() error
+}
+
+type jwtIdIdentifier struct {
+	F Format `json:"format"`
+	I string `json:"iss"`
+	J string `json:"jti"`
+}
+
+func (id *jwtIdIdentifier) Format() Format {
+	return id.F
+}
+
+func (id *jwtIdIdentifier) Issuer() string {
+	return id.I
+}
+
+func (id *jwtIdIdentifier) JwtId() string {
+	return id.J
+}
+
+func (id *jwtIdIdentifier) Validate() error {
+	if id.I == "" {
+		return ErrEmptyIssuer
+	}
+
+	if id.J == "" {
+		return ErrEmptyJwtId
+	}
+
+	return nil
+}
+
+func (id *jwtIdIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *jwtIdIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *jwtIdIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *jwtIdIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "jwtIdIdentifier")
+}
+
+func (id *jwtIdIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatJwtId)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*jwtIdIdentifier)
+	return nil
+}
+
+// NewJwtIdIdentifier creates new instance of JwtIdIdentifier.
+// The argument "issuer" and "jwtId" is required. If either one of them is empty, this function returns error.
+func NewJwtIdIdentifier(issuer string, jwtId string) (JwtIdIdentifier, error) {
+	id := &jwtIdIdentifier{
+		F: FormatJwtId,
+		I: issuer,
+		J: jwtId,
+	}
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
@@ -0,0 +1,52 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pinzolo/secevsubid"
+	"testing"
+)
+
+func TestJwtIdIdentifier(t *testing.T) {
+	wantIss := "https://idp.example.com/123456789/"
+	wantJti := "B70BA622-9515-4353-A866-823539EECBC8"
+	id, err := secevsubid.NewJwtIdIdentifier(wantIss, wantJti)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if id.Format() != secevsubid.FormatJwtId {
+		t.Errorf("invalid format: got = %s, want = %s", id.Format(), secevsubid.FormatJwtId)
+	}
+	if id.Issuer() != wantIss {
+		t.Errorf("invalid issuer: got = %s, want = %s", id.Issuer(), wantIss)
+	}
+	if id.JwtId() != wantJti {
+		t.Errorf("invalid jti: got = %s, want = %s", id.JwtId(), wantJti)
+	}
+
+	wantJSON := fmt.Sprintf(`{"format":"jwt_id","iss":"%s","jti":"%s"}`, wantIss, wantJti)
+	b, err := json.Marshal(id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(b) != wantJSON {
+		t.Errorf("invalid JSON conversion: got = %s, want = %s", string(b), wantJSON)
+	}
+}
+
+func TestJwtIdIdentifierWithEmptyIssuer(t *testing.T) {
+	_, err := secevsubid.NewJwtIdIdentifier("", "B70BA622-9515-4353-A866-823539EECBC8")
+	if err == nil {
+		t.Error("error should be raised when issuer is empty")
+	}
+}
+
+func TestJwtIdIdentifierWithEmptyJwtId(t *testing.T) {
+	_, err := secevsubid.NewJwtIdIdentifier("https://idp.example.com/123456789/", "")
+	if err == nil {
+		t.Error("error should be raised when jti is empty")
+	}
+}
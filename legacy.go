@@ -0,0 +1,231 @@
+package secevsubid
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LegacySubjectType is the value of "subject_type" field used by subjects of pre-RFC RISC drafts.
+type LegacySubjectType string
+
+const (
+	// LegacySubjectTypeIssuerSubject is the legacy subject type corresponding to Issuer and Subject Identifier Format.
+	LegacySubjectTypeIssuerSubject = LegacySubjectType("iss-sub")
+	// LegacySubjectTypeEmail is the legacy subject type corresponding to Email Identifier Format.
+	LegacySubjectTypeEmail = LegacySubjectType("email")
+	// LegacySubjectTypePhone is the legacy subject type corresponding to Phone Number Identifier Format.
+	LegacySubjectTypePhone = LegacySubjectType("phone")
+	// LegacySubjectTypeIdTokenClaims is the legacy subject type holding claims of ID token.
+	// It is decoded to Aliases Identifier Format built from whichever of "iss" and "sub", "email" and "phone_number" claims are present.
+	LegacySubjectTypeIdTokenClaims = LegacySubjectType("id_token_claims")
+	// LegacySubjectTypeJwtId is the legacy subject type corresponding to JWT ID Subject Identifier Format.
+	LegacySubjectTypeJwtId = LegacySubjectType("jwt-id")
+	// LegacySubjectTypeSamlAssertionId is the legacy subject type corresponding to SAML Assertion ID Subject Identifier Format.
+	LegacySubjectTypeSamlAssertionId = LegacySubjectType("saml-assertion-id")
+
+	// FieldSubjectType is the field name for "subject_type" field.
+	// This field is used in all legacy subjects instead of "format" field.
+	fieldSubjectType = "subject_type"
+)
+
+// LegacyWrapper is Wrapper for the legacy RISC subject.
+// It is decoded from both of the legacy subject and the current identifier formats,
+// and encoded to the legacy subject.
+type LegacyWrapper struct {
+	v SubjectIdentifier
+}
+
+// Value returns the instance of SubjectIdentifier held internally.
+func (w *LegacyWrapper) Value() SubjectIdentifier {
+	return w.v
+}
+
+// MarshalJSON implements json.Marshaler.
+// Returns legacy JSON representation of the SubjectIdentifier held internally.
+func (w *LegacyWrapper) MarshalJSON() ([]byte, error) {
+	if w == nil {
+		return nil, ErrNoSubject
+	}
+	return EncodeLegacyJSON(w.v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (w *LegacyWrapper) UnmarshalJSON(b []byte) error {
+	id, err := DecodeLegacyJSON(b)
+	if err != nil {
+		return err
+	}
+
+	w.v = id
+	return nil
+}
+
+// NewLegacyWrapper creates new instance of LegacyWrapper.
+func NewLegacyWrapper(id SubjectIdentifier) *LegacyWrapper {
+	return &LegacyWrapper{v: id}
+}
+
+// DecodeLegacyJSON decodes to the appropriate SubjectIdentifier instance from the legacy RISC subject.
+// Which format is decoded is determined by the value of the "subject_type" field.
+// If the JSON object has "format" field instead, it is decoded in the same way as DecodeJSON.
+func DecodeLegacyJSON(b []byte) (SubjectIdentifier, error) {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	if _, ok := m[fieldFormat]; ok {
+		return decodeIdentifier(m)
+	}
+
+	return decodeLegacyIdentifier(m)
+}
+
+func decodeLegacyIdentifier(m map[string]interface{}) (SubjectIdentifier, error) {
+	t, ok := m[fieldSubjectType].(string)
+	if !ok {
+		return nil, ErrNoSubjectType
+	}
+
+	switch LegacySubjectType(t) {
+	case LegacySubjectTypeIdTokenClaims:
+		return decodeIdTokenClaims(m)
+	case LegacySubjectTypeIssuerSubject:
+		return NewIssuerSubjectIdentifier(extractStringValue(m, fieldIssuer), extractStringValue(m, fieldSubject))
+	case LegacySubjectTypeEmail:
+		return NewEmailIdentifier(extractStringValue(m, fieldEmail))
+	case LegacySubjectTypePhone:
+		return NewPhoneNumberIdentifier(extractStringValue(m, fieldPhoneNumber))
+	case LegacySubjectTypeJwtId:
+		return NewJwtIdIdentifier(extractStringValue(m, fieldIssuer), extractStringValue(m, fieldJwtId))
+	case LegacySubjectTypeSamlAssertionId:
+		return NewSamlAssertionIdIdentifier(extractStringValue(m, fieldSamlIssuer), extractStringValue(m, fieldAssertionId))
+	}
+
+	return nil, fmt.Errorf("unknown subject_type: %s", t)
+}
+
+func decodeIdTokenClaims(m map[string]interface{}) (SubjectIdentifier, error) {
+	var ids []SubjectIdentifier
+	_, hasIss := m[fieldIssuer]
+	_, hasSub := m[fieldSubject]
+	if hasIss || hasSub {
+		id, err := NewIssuerSubjectIdentifier(extractStringValue(m, fieldIssuer), extractStringValue(m, fieldSubject))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if _, ok := m[fieldEmail]; ok {
+		id, err := NewEmailIdentifier(extractStringValue(m, fieldEmail))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if _, ok := m[fieldPhoneNumber]; ok {
+		id, err := NewPhoneNumberIdentifier(extractStringValue(m, fieldPhoneNumber))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoSubject
+	}
+
+	return NewAliasesIdentifier(ids...)
+}
+
+type legacyIssSub struct {
+	T LegacySubjectType `json:"subject_type"`
+	I string            `json:"iss"`
+	S string            `json:"sub"`
+}
+
+type legacyIdTokenClaims struct {
+	T LegacySubjectType `json:"subject_type"`
+	I string            `json:"iss,omitempty"`
+	S string            `json:"sub,omitempty"`
+	E string            `json:"email,omitempty"`
+	N string            `json:"phone_number,omitempty"`
+}
+
+type legacyEmail struct {
+	T LegacySubjectType `json:"subject_type"`
+	E string            `json:"email"`
+}
+
+type legacyPhone struct {
+	T LegacySubjectType `json:"subject_type"`
+	N string            `json:"phone_number"`
+}
+
+type legacyJwtId struct {
+	T LegacySubjectType `json:"subject_type"`
+	I string            `json:"iss"`
+	J string            `json:"jti"`
+}
+
+type legacySamlAssertionId struct {
+	T LegacySubjectType `json:"subject_type"`
+	I string            `json:"issuer"`
+	A string            `json:"assertion_id"`
+}
+
+// EncodeLegacyJSON encodes the SubjectIdentifier to the legacy RISC subject for receivers that do not support "format" field.
+// Aliases Identifier whose members are at most one each of iss_sub, email and phone_number is encoded to "id_token_claims".
+// Formats which have no legacy subject type, e.g. Opaque Identifier Format, result in ErrNoLegacySubjectType.
+func EncodeLegacyJSON(id SubjectIdentifier) ([]byte, error) {
+	if id == nil {
+		return nil, ErrNoSubject
+	}
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		return encodeLegacyIdTokenClaims(v)
+	case IssuerSubjectIdentifier:
+		return json.Marshal(&legacyIssSub{T: LegacySubjectTypeIssuerSubject, I: v.Issuer(), S: v.Subject()})
+	case EmailIdentifier:
+		return json.Marshal(&legacyEmail{T: LegacySubjectTypeEmail, E: v.Email()})
+	case PhoneNumberIdentifier:
+		return json.Marshal(&legacyPhone{T: LegacySubjectTypePhone, N: v.PhoneNumber()})
+	case JwtIdIdentifier:
+		return json.Marshal(&legacyJwtId{T: LegacySubjectTypeJwtId, I: v.Issuer(), J: v.JwtId()})
+	case SamlAssertionIdIdentifier:
+		return json.Marshal(&legacySamlAssertionId{T: LegacySubjectTypeSamlAssertionId, I: v.Issuer(), A: v.AssertionId()})
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoLegacySubjectType, id.Format())
+}
+
+func encodeLegacyIdTokenClaims(id AliasesIdentifier) ([]byte, error) {
+	c := &legacyIdTokenClaims{T: LegacySubjectTypeIdTokenClaims}
+	for _, m := range id.Identifiers() {
+		switch m.Format() {
+		case FormatIssuerSubject:
+			v := m.(IssuerSubjectIdentifier)
+			if c.I != "" {
+				return nil, fmt.Errorf("%w: aliases with multiple %s", ErrNoLegacySubjectType, m.Format())
+			}
+			c.I, c.S = v.Issuer(), v.Subject()
+		case FormatEmail:
+			if c.E != "" {
+				return nil, fmt.Errorf("%w: aliases with multiple %s", ErrNoLegacySubjectType, m.Format())
+			}
+			c.E = m.(EmailIdentifier).Email()
+		case FormatPhoneNumber:
+			if c.N != "" {
+				return nil, fmt.Errorf("%w: aliases with multiple %s", ErrNoLegacySubjectType, m.Format())
+			}
+			c.N = m.(PhoneNumberIdentifier).PhoneNumber()
+		default:
+			return nil, fmt.Errorf("%w: aliases with %s", ErrNoLegacySubjectType, m.Format())
+		}
+	}
+
+	return json.Marshal(c)
+}
@@ -0,0 +1,240 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeLegacyJSON(t *testing.T) {
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phoneNum, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	jwtId, _ := secevsubid.NewJwtIdIdentifier("https://issuer.example.com/", "B70BA622-9515-4353-A866-823539EECBC8")
+	samlAssertionId, _ := secevsubid.NewSamlAssertionIdIdentifier("https://issuer.example.com/", "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6")
+	claims, _ := secevsubid.NewAliasesIdentifier(issSub, email)
+	emailClaims, _ := secevsubid.NewAliasesIdentifier(email, phoneNum)
+
+	tests := []struct {
+		name    string
+		json    string
+		want    secevsubid.SubjectIdentifier
+		wantErr bool
+	}{
+		{
+			name:    "iss-sub",
+			json:    `{"subject_type":"iss-sub","iss":"https://issuer.example.com/","sub":"145234573"}`,
+			want:    issSub,
+			wantErr: false,
+		},
+		{
+			name:    "email",
+			json:    `{"subject_type":"email","email":"user@example.com"}`,
+			want:    email,
+			wantErr: false,
+		},
+		{
+			name:    "phone",
+			json:    `{"subject_type":"phone","phone_number":"+12065550100"}`,
+			want:    phoneNum,
+			wantErr: false,
+		},
+		{
+			name:    "id_token_claims",
+			json:    `{"subject_type":"id_token_claims","iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com"}`,
+			want:    claims,
+			wantErr: false,
+		},
+		{
+			name:    "id_token_claims without iss and sub",
+			json:    `{"subject_type":"id_token_claims","email":"user@example.com","phone_number":"+12065550100"}`,
+			want:    emailClaims,
+			wantErr: false,
+		},
+		{
+			name:    "id_token_claims without sub",
+			json:    `{"subject_type":"id_token_claims","iss":"https://issuer.example.com/","email":"user@example.com"}`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "id_token_claims without claims",
+			json:    `{"subject_type":"id_token_claims"}`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "id_token_claims with invalid email",
+			json:    `{"subject_type":"id_token_claims","email":1}`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "jwt-id",
+			json:    `{"subject_type":"jwt-id","iss":"https://issuer.example.com/","jti":"B70BA622-9515-4353-A866-823539EECBC8"}`,
+			want:    jwtId,
+			wantErr: false,
+		},
+		{
+			name:    "saml-assertion-id",
+			json:    `{"subject_type":"saml-assertion-id","issuer":"https://issuer.example.com/","assertion_id":"_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6"}`,
+			want:    samlAssertionId,
+			wantErr: false,
+		},
+		{
+			name:    "current format",
+			json:    `{"format":"email","email":"user@example.com"}`,
+			want:    email,
+			wantErr: false,
+		},
+		{
+			name:    "no subject_type",
+			json:    `{"email":"user@example.com"}`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown subject_type",
+			json:    `{"subject_type":"unknown","email":"user@example.com"}`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			json:    `{"subject_type":"iss-sub","iss":"https://issuer.example.com/"}`,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.DecodeLegacyJSON([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeLegacyJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeLegacyJSON() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeLegacyJSON(t *testing.T) {
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	phoneNum, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("11112222333344445555")
+
+	tests := []struct {
+		name    string
+		id      secevsubid.SubjectIdentifier
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "iss_sub",
+			id:      issSub,
+			want:    `{"subject_type":"iss-sub","iss":"https://issuer.example.com/","sub":"145234573"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "phone_number",
+			id:      phoneNum,
+			want:    `{"subject_type":"phone","phone_number":"+12065550100"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "nil",
+			id:      nil,
+			want:    "",
+			wantErr: secevsubid.ErrNoSubject,
+		},
+		{
+			name:    "opaque",
+			id:      opaque,
+			want:    "",
+			wantErr: secevsubid.ErrNoLegacySubjectType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := secevsubid.EncodeLegacyJSON(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("EncodeLegacyJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(b) != tt.want {
+				t.Errorf("EncodeLegacyJSON() got = %s, want %s", string(b), tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyWrapper(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	b, err := json.Marshal(secevsubid.NewLegacyWrapper(email))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := `{"subject_type":"email","email":"user@example.com"}`
+	if string(b) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", string(b), want)
+	}
+
+	w := &secevsubid.LegacyWrapper{}
+	if err = json.Unmarshal(b, w); err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(w.Value(), email) {
+		t.Errorf("UnmarshalJSON() got = %v, want %v", w.Value(), email)
+	}
+}
+
+func TestLegacyWrapper_MarshalJSONWithoutSubject(t *testing.T) {
+	var nilWrapper *secevsubid.LegacyWrapper
+	for _, w := range []*secevsubid.LegacyWrapper{nilWrapper, {}, secevsubid.NewLegacyWrapper(nil)} {
+		if _, err := w.MarshalJSON(); !errors.Is(err, secevsubid.ErrNoSubject) {
+			t.Errorf("MarshalJSON() error = %v, wantErr %v", err, secevsubid.ErrNoSubject)
+		}
+	}
+}
+
+func TestLegacyIdTokenClaims(t *testing.T) {
+	if _, err := secevsubid.DecodeLegacyJSON([]byte(`{"subject_type":"id_token_claims"}`)); !errors.Is(err, secevsubid.ErrNoSubject) {
+		t.Errorf("DecodeLegacyJSON() error = %v, wantErr %v", err, secevsubid.ErrNoSubject)
+	}
+
+	for _, s := range []string{
+		`{"subject_type":"id_token_claims","iss":"https://issuer.example.com/","sub":"145234573","email":"user@example.com","phone_number":"+12065550100"}`,
+		`{"subject_type":"id_token_claims","email":"user@example.com"}`,
+	} {
+		w := &secevsubid.LegacyWrapper{}
+		if err := json.Unmarshal([]byte(s), w); err != nil {
+			t.Error(err)
+			return
+		}
+		b, err := json.Marshal(w)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(b) != s {
+			t.Errorf("MarshalJSON() = %s, want %s", b, s)
+		}
+	}
+
+	opaque, _ := secevsubid.NewOpaqueIdentifier("11112222333344445555")
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	other, _ := secevsubid.NewEmailIdentifier("other@example.com")
+	withOpaque, _ := secevsubid.NewAliasesIdentifier(email, opaque)
+	twoEmails, _ := secevsubid.NewAliasesIdentifier(email, other)
+	for _, id := range []secevsubid.SubjectIdentifier{withOpaque, twoEmails} {
+		if _, err := secevsubid.EncodeLegacyJSON(id); !errors.Is(err, secevsubid.ErrNoLegacySubjectType) {
+			t.Errorf("EncodeLegacyJSON() error = %v, wantErr %v", err, secevsubid.ErrNoLegacySubjectType)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package secevsubid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// MaskingPolicy determines how values of identifiers are masked when they are logged or printed.
+// Identifier types implement fmt.Stringer, fmt.GoStringer and slog.LogValuer with the global policy set by SetMaskingPolicy.
+// Since identifiers have Format method returning Format, they cannot implement fmt.Formatter.
+// Use Masked for fmt.Formatter and a policy per call, or Wrapper which implements fmt.Formatter with the global policy.
+type MaskingPolicy int32
+
+const (
+	// MaskPartial keeps non-identifying parts of values, e.g. "u***@example.com" and "+1206*****00".
+	// Values which cannot be partially masked such as opaque ids are hashed. This is the default policy.
+	MaskPartial MaskingPolicy = iota
+	// MaskHash replaces values with truncated SHA-256 hashes, so that the same values are correlated in logs.
+	// Hashes are not keyed, so use Pseudonymizer where guessing values from hashes must be prevented.
+	MaskHash
+	// MaskFull replaces all values with "***".
+	MaskFull
+	// MaskNone prints values as they are.
+	MaskNone
+)
+
+const maskedValue = "***"
+
+var maskingPolicy int32
+
+// SetMaskingPolicy sets the global MaskingPolicy.
+func SetMaskingPolicy(p MaskingPolicy) {
+	atomic.StoreInt32(&maskingPolicy, int32(p))
+}
+
+// CurrentMaskingPolicy returns the global MaskingPolicy.
+func CurrentMaskingPolicy() MaskingPolicy {
+	return MaskingPolicy(atomic.LoadInt32(&maskingPolicy))
+}
+
+// Mask returns the string representation of the identifier masked by the policy, e.g. "email:u***@example.com".
+// Identifiers with several members are represented as "iss_sub:{iss=https://issuer.example.com/ sub=#1a2b3c4d5e6f}".
+func Mask(id SubjectIdentifier, p MaskingPolicy) string {
+	if id == nil {
+		return "<nil>"
+	}
+
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		ss := make([]string, 0, len(v.Identifiers()))
+		for _, m := range v.Identifiers() {
+			ss = append(ss, Mask(m, p))
+		}
+		return string(FormatAliases) + ":[" + strings.Join(ss, " ") + "]"
+	case ComplexIdentifier:
+		ss := make([]string, 0, len(complexMemberFields))
+		members := v.Members()
+		for _, name := range complexMemberFields {
+			if m, ok := members[name]; ok {
+				ss = append(ss, name+"="+Mask(m, p))
+			}
+		}
+		return string(FormatComplex) + ":{" + strings.Join(ss, " ") + "}"
+	}
+
+	fs := maskFields(id, p)
+	if len(fs) == 1 {
+		return string(id.Format()) + ":" + fs[0].value
+	}
+
+	ss := make([]string, len(fs))
+	for i, f := range fs {
+		ss[i] = f.name + "=" + f.value
+	}
+	return string(id.Format()) + ":{" + strings.Join(ss, " ") + "}"
+}
+
+// MaskedIdentifier holds SubjectIdentifier with MaskingPolicy applied when it is printed or logged.
+type MaskedIdentifier struct {
+	// Identifier is the identifier to be masked.
+	Identifier SubjectIdentifier
+	// Policy is the policy applied to the identifier.
+	Policy MaskingPolicy
+}
+
+// Masked returns MaskedIdentifier for printing or logging the identifier with the policy instead of the global policy.
+func Masked(id SubjectIdentifier, p MaskingPolicy) MaskedIdentifier {
+	return MaskedIdentifier{Identifier: id, Policy: p}
+}
+
+// String implements fmt.Stringer.
+func (m MaskedIdentifier) String() string {
+	return Mask(m.Identifier, m.Policy)
+}
+
+// Format implements fmt.Formatter.
+func (m MaskedIdentifier) Format(s fmt.State, verb rune) {
+	formatMasked(s, verb, m.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (m MaskedIdentifier) LogValue() slog.Value {
+	return maskedLogValue(m.Identifier, m.Policy)
+}
+
+// String implements fmt.Stringer with the global MaskingPolicy.
+func (w *Wrapper) String() string {
+	return Mask(w.v, CurrentMaskingPolicy())
+}
+
+// Format implements fmt.Formatter with the global MaskingPolicy.
+func (w *Wrapper) Format(s fmt.State, verb rune) {
+	formatMasked(s, verb, w.String())
+}
+
+// LogValue implements slog.LogValuer with the global MaskingPolicy.
+func (w *Wrapper) LogValue() slog.Value {
+	return maskedLogValue(w.v, CurrentMaskingPolicy())
+}
+
+func formatMasked(s fmt.State, verb rune, str string) {
+	switch verb {
+	case 'v', 's':
+		_, _ = fmt.Fprint(s, str)
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", str)
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(%s)", verb, str)
+	}
+}
+
+func maskedLogValue(id SubjectIdentifier, p MaskingPolicy) slog.Value {
+	if id == nil {
+		return slog.StringValue("<nil>")
+	}
+
+	attrs := []slog.Attr{slog.String(fieldFormat, string(id.Format()))}
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		ss := make([]string, 0, len(v.Identifiers()))
+		for _, m := range v.Identifiers() {
+			ss = append(ss, Mask(m, p))
+		}
+		attrs = append(attrs, slog.Any(fieldIdentifiers, ss))
+	case ComplexIdentifier:
+		attrs = attrs[:0]
+		members := v.Members()
+		for _, name := range complexMemberFields {
+			if m, ok := members[name]; ok {
+				attrs = append(attrs, slog.Attr{Key: name, Value: maskedLogValue(m, p)})
+			}
+		}
+	default:
+		for _, f := range maskFields(id, p) {
+			attrs = append(attrs, slog.String(f.name, f.value))
+		}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+type maskedField struct {
+	name  string
+	value string
+}
+
+func maskFields(id SubjectIdentifier, p MaskingPolicy) []maskedField {
+	switch id.Format() {
+	case FormatAccount:
+		if v, ok := id.(AccountIdentifier); ok {
+			return []maskedField{{fieldUri, maskValue(v.Uri(), p, maskAccount)}}
+		}
+	case FormatEmail:
+		if v, ok := id.(EmailIdentifier); ok {
+			return []maskedField{{fieldEmail, maskValue(v.Email(), p, maskEmail)}}
+		}
+	case FormatIssuerSubject:
+		if v, ok := id.(IssuerSubjectIdentifier); ok {
+			return []maskedField{
+				{fieldIssuer, maskIssuer(v.Issuer(), p)},
+				{fieldSubject, maskValue(v.Subject(), p, hashValue)},
+			}
+		}
+	case FormatOpaque:
+		if v, ok := id.(OpaqueIdentifier); ok {
+			return []maskedField{{fieldId, maskValue(v.Id(), p, hashValue)}}
+		}
+	case FormatPhoneNumber:
+		if v, ok := id.(PhoneNumberIdentifier); ok {
+			return []maskedField{{fieldPhoneNumber, maskValue(v.PhoneNumber(), p, maskPhoneNumber)}}
+		}
+	case FormatDid:
+		if v, ok := id.(DidIdentifier); ok {
+			return []maskedField{{fieldUrl, maskValue(v.Url(), p, maskDid)}}
+		}
+	case FormatUri:
+		if v, ok := id.(UriIdentifier); ok {
+			return []maskedField{{fieldUri, maskValue(v.Uri(), p, maskUri)}}
+		}
+	case FormatJwtId:
+		if v, ok := id.(JwtIdIdentifier); ok {
+			return []maskedField{
+				{fieldIssuer, maskIssuer(v.Issuer(), p)},
+				{fieldJwtId, maskValue(v.JwtId(), p, keepValue)},
+			}
+		}
+	case FormatSamlAssertionId:
+		if v, ok := id.(SamlAssertionIdIdentifier); ok {
+			return []maskedField{
+				{fieldSamlIssuer, maskIssuer(v.Issuer(), p)},
+				{fieldAssertionId, maskValue(v.AssertionId(), p, keepValue)},
+			}
+		}
+	}
+
+	return []maskedField{{"value", maskedValue}}
+}
+
+// maskIssuer keeps issuers which are not personal information except for MaskFull.
+func maskIssuer(s string, p MaskingPolicy) string {
+	if p == MaskFull {
+		return maskedValue
+	}
+	return s
+}
+
+func maskValue(s string, p MaskingPolicy, partial func(string) string) string {
+	switch p {
+	case MaskNone:
+		return s
+	case MaskHash:
+		return hashValue(s)
+	case MaskFull:
+		return maskedValue
+	}
+
+	return partial(s)
+}
+
+func keepValue(s string) string {
+	return s
+}
+
+func hashValue(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "#" + hex.EncodeToString(sum[:6])
+}
+
+// maskEmail keeps the first character of the local part and the domain.
+// A local part of one character is masked entirely, so at least one character is always masked.
+func maskEmail(s string) string {
+	i := strings.LastIndex(s, "@")
+	if i <= 0 {
+		return maskedValue
+	}
+
+	local := []rune(s[:i])
+	if len(local) == 1 {
+		return maskedValue + s[i:]
+	}
+	return string(local[:1]) + maskedValue + s[i:]
+}
+
+func maskPhoneNumber(s string) string {
+	const head, tail = 5, 2
+	if len(s) <= head+tail {
+		return strings.Repeat("*", len(s))
+	}
+
+	return s[:head] + strings.Repeat("*", len(s)-head-tail) + s[len(s)-tail:]
+}
+
+func maskAccount(s string) string {
+	if strings.HasPrefix(s, "acct:") {
+		return "acct:" + maskEmail(strings.TrimPrefix(s, "acct:"))
+	}
+
+	return maskUri(s)
+}
+
+func maskUri(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return hashValue(s)
+	}
+	if u.Host != "" {
+		return u.Scheme + "://" + u.Host + "/" + maskedValue
+	}
+
+	return u.Scheme + ":" + hashValue(u.Opaque)
+}
+
+func maskDid(s string) string {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return hashValue(s)
+	}
+
+	return parts[0] + ":" + parts[1] + ":" + hashValue(parts[2])
+}
@@ -0,0 +1,112 @@
+package secevsubid_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pinzolo/secevsubid"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	shortEmail, _ := secevsubid.NewEmailIdentifier("u@example.com")
+	multiByteEmail, _ := secevsubid.NewEmailIdentifier("ユーザー@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	acct, _ := secevsubid.NewAccountIdentifier("acct:user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("11112222333344445555")
+	did, _ := secevsubid.NewDidIdentifier("did:example:123456")
+	uri, _ := secevsubid.NewUriIdentifier("https://user.example.com/profile")
+	jwtId, _ := secevsubid.NewJwtIdIdentifier("https://issuer.example.com/", "B70BA622")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, phone)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: email, Tenant: opaque})
+
+	tests := []struct {
+		name   string
+		id     secevsubid.SubjectIdentifier
+		policy secevsubid.MaskingPolicy
+		want   string
+	}{
+		{name: "email", id: email, policy: secevsubid.MaskPartial, want: "email:u***@example.com"},
+		{name: "email with short local part", id: shortEmail, policy: secevsubid.MaskPartial, want: "email:***@example.com"},
+		{name: "email with multi-byte local part", id: multiByteEmail, policy: secevsubid.MaskPartial, want: "email:ユ***@example.com"},
+		{name: "phone", id: phone, policy: secevsubid.MaskPartial, want: "phone_number:+1206*****00"},
+		{name: "account", id: acct, policy: secevsubid.MaskPartial, want: "account:acct:u***@example.com"},
+		{name: "iss_sub", id: issSub, policy: secevsubid.MaskPartial, want: "iss_sub:{iss=https://issuer.example.com/ sub=#" + hashOf("145234573") + "}"},
+		{name: "opaque", id: opaque, policy: secevsubid.MaskPartial, want: "opaque:#" + hashOf("11112222333344445555")},
+		{name: "did", id: did, policy: secevsubid.MaskPartial, want: "did:did:example:#" + hashOf("123456")},
+		{name: "uri", id: uri, policy: secevsubid.MaskPartial, want: "uri:https://user.example.com/***"},
+		{name: "jwt_id", id: jwtId, policy: secevsubid.MaskPartial, want: "jwt_id:{iss=https://issuer.example.com/ jti=B70BA622}"},
+		{name: "aliases", id: aliases, policy: secevsubid.MaskPartial, want: "aliases:[email:u***@example.com phone_number:+1206*****00]"},
+		{name: "complex", id: complexSubject, policy: secevsubid.MaskFull, want: "complex:{user=email:*** tenant=opaque:***}"},
+		{name: "hash", id: email, policy: secevsubid.MaskHash, want: "email:#" + hashOf("user@example.com")},
+		{name: "full", id: issSub, policy: secevsubid.MaskFull, want: "iss_sub:{iss=*** sub=***}"},
+		{name: "none", id: email, policy: secevsubid.MaskNone, want: "email:user@example.com"},
+		{name: "nil", id: nil, policy: secevsubid.MaskPartial, want: "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secevsubid.Mask(tt.id, tt.policy); got != tt.want {
+				t.Errorf("Mask() = %v, want %v", got, tt.want)
+			}
+			if got := fmt.Sprintf("%v", secevsubid.Masked(tt.id, tt.policy)); got != tt.want {
+				t.Errorf("Masked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func hashOf(s string) string {
+	id, _ := secevsubid.NewOpaqueIdentifier(s)
+	return strings.TrimPrefix(secevsubid.Mask(id, secevsubid.MaskHash), "opaque:#")
+}
+
+func TestSetMaskingPolicy(t *testing.T) {
+	defer secevsubid.SetMaskingPolicy(secevsubid.MaskPartial)
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	w := secevsubid.NewWrapper(email)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if got := fmt.Sprintf(format, email); got != "email:u***@example.com" {
+			t.Errorf("Sprintf(%q) = %v, want masked", format, got)
+		}
+		if got := fmt.Sprintf(format, w); got != "email:u***@example.com" {
+			t.Errorf("Sprintf(%q) with Wrapper = %v, want masked", format, got)
+		}
+	}
+
+	secevsubid.SetMaskingPolicy(secevsubid.MaskFull)
+	if got := fmt.Sprint(email); got != "email:***" {
+		t.Errorf("Sprint() = %v, want email:***", got)
+	}
+	if got := fmt.Sprintf("%q", w); got != `"email:***"` {
+		t.Errorf("Sprintf(%%q) = %v, want quoted", got)
+	}
+}
+
+func TestMaskedIdentifier_LogValue(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("received", "sub_id", email, "aliases", aliases, "full", secevsubid.Masked(issSub, secevsubid.MaskFull))
+
+	got := buf.String()
+	wants := []string{
+		`"sub_id":{"format":"email","email":"u***@example.com"}`,
+		`"aliases":{"format":"aliases","identifiers":["email:u***@example.com","iss_sub:{iss=https://issuer.example.com/ sub=#`,
+		`"full":{"format":"iss_sub","iss":"***","sub":"***"}`,
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("log = %v, want to contain %v", got, want)
+		}
+	}
+	if strings.Contains(got, "user@example.com") || strings.Contains(got, "145234573") {
+		t.Errorf("log = %v, want no raw values", got)
+	}
+}
@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // OpaqueIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Opaque Identifier Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-opaque-identifier-format
@@ -34,6 +39,32 @@ func (id *opaqueIdentifier) Validate() error {
 	return nil
 }
 
+func (id *opaqueIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *opaqueIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *opaqueIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *opaqueIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "opaqueIdentifier")
+}
+
+func (id *opaqueIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatOpaque)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*opaqueIdentifier)
+	return nil
+}
+
 // NewOpaqueIdentifier creates new instance of OpaqueIdentifier.
 // The argument "id" is required. If it's empty, this function returns error.
 func NewOpaqueIdentifier(id string) (OpaqueIdentifier, error) {
@@ -0,0 +1,123 @@
+package secevsubid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// SectorIdentifier returns the sector identifier of the URL, which is its host component as in OpenID Connect pairwise subjects.
+// Reference: https://openid.net/specs/openid-connect-core-1_0.html#PairwiseAlg
+func SectorIdentifier(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("no host in url: %s", rawURL)
+	}
+
+	return u.Hostname(), nil
+}
+
+// PairwiseSubject derives the subject value of the local user for the sector by HMAC-SHA256 with the secret.
+// The same arguments always derive the same value, and different sectors derive values which cannot be correlated without the secret.
+func PairwiseSubject(localId string, sectorId string, secret []byte) (string, error) {
+	if localId == "" || sectorId == "" {
+		return "", ErrEmptyId
+	}
+	if len(secret) == 0 {
+		return "", ErrInvalidKey
+	}
+
+	h := hmac.New(sha256.New, secret)
+	_, _ = h.Write([]byte(sectorId))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(localId))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// NewPairwiseOpaqueIdentifier creates new instance of OpaqueIdentifier holding the pairwise subject value.
+// See PairwiseSubject for details.
+func NewPairwiseOpaqueIdentifier(localId string, sectorId string, secret []byte) (OpaqueIdentifier, error) {
+	sub, err := PairwiseSubject(localId, sectorId, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOpaqueIdentifier(sub)
+}
+
+// NewPairwiseIssuerSubjectIdentifier creates new instance of IssuerSubjectIdentifier whose "sub" is the pairwise subject value.
+// See PairwiseSubject for details.
+func NewPairwiseIssuerSubjectIdentifier(issuer string, localId string, sectorId string, secret []byte) (IssuerSubjectIdentifier, error) {
+	sub, err := PairwiseSubject(localId, sectorId, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewIssuerSubjectIdentifier(issuer, sub)
+}
+
+// PairwiseTable derives pairwise subject values and records them to look up the local user from them.
+// The transmitter uses it to resolve pairwise subjects in requests from receivers, e.g. adding subjects to streams.
+type PairwiseTable struct {
+	secret []byte
+	mu     sync.RWMutex
+	users  map[string]map[string]string
+}
+
+// NewPairwiseTable creates new instance of PairwiseTable.
+func NewPairwiseTable(secret []byte) *PairwiseTable {
+	return &PairwiseTable{
+		secret: append([]byte(nil), secret...),
+		users:  make(map[string]map[string]string),
+	}
+}
+
+// Subject derives the pairwise subject value of the local user for the sector and records it.
+func (t *PairwiseTable) Subject(localId string, sectorId string) (string, error) {
+	sub, err := PairwiseSubject(localId, sectorId, t.secret)
+	if err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m, ok := t.users[sectorId]
+	if !ok {
+		m = make(map[string]string)
+		t.users[sectorId] = m
+	}
+	m[sub] = localId
+	return sub, nil
+}
+
+// Lookup returns the local user ID of the pairwise subject value for the sector.
+// If the value is not recorded, it returns ErrUnresolvedSubject.
+func (t *PairwiseTable) Lookup(sectorId string, sub string) (string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if id, ok := t.users[sectorId][sub]; ok {
+		return id, nil
+	}
+
+	return "", fmt.Errorf("%w: %s in %s", ErrUnresolvedSubject, sub, sectorId)
+}
+
+// LookupIdentifier returns the local user ID of the pairwise OpaqueIdentifier or IssuerSubjectIdentifier for the sector.
+func (t *PairwiseTable) LookupIdentifier(sectorId string, id SubjectIdentifier) (string, error) {
+	switch v := id.(type) {
+	case OpaqueIdentifier:
+		return t.Lookup(sectorId, v.Id())
+	case IssuerSubjectIdentifier:
+		return t.Lookup(sectorId, v.Subject())
+	case nil:
+		return "", ErrNoSubject
+	}
+
+	return "", fmt.Errorf("%w: %s is not pairwise", ErrUnresolvedSubject, id.Format())
+}
@@ -0,0 +1,98 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"testing"
+)
+
+func TestSectorIdentifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "url", url: "https://receiver.example.com:8443/events", want: "receiver.example.com"},
+		{name: "no host", url: "receiver", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.SectorIdentifier(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SectorIdentifier() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SectorIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairwiseSubject(t *testing.T) {
+	secret := []byte("secret")
+	a1, _ := secevsubid.PairwiseSubject("user1", "a.example.com", secret)
+	a2, _ := secevsubid.PairwiseSubject("user1", "a.example.com", secret)
+	b, _ := secevsubid.PairwiseSubject("user1", "b.example.com", secret)
+	other, _ := secevsubid.PairwiseSubject("user2", "a.example.com", secret)
+	if a1 != a2 {
+		t.Errorf("PairwiseSubject() = %v and %v, want deterministic", a1, a2)
+	}
+	if a1 == b || a1 == other {
+		t.Errorf("PairwiseSubject() = %v, want different from %v and %v", a1, b, other)
+	}
+
+	if _, err := secevsubid.PairwiseSubject("", "a.example.com", secret); err != secevsubid.ErrEmptyId {
+		t.Errorf("PairwiseSubject() error = %v, wantErr %v", err, secevsubid.ErrEmptyId)
+	}
+	if _, err := secevsubid.PairwiseSubject("user1", "a.example.com", nil); err != secevsubid.ErrInvalidKey {
+		t.Errorf("PairwiseSubject() error = %v, wantErr %v", err, secevsubid.ErrInvalidKey)
+	}
+
+	id, err := secevsubid.NewPairwiseIssuerSubjectIdentifier("https://issuer.example.com/", "user1", "a.example.com", secret)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if id.Subject() != a1 {
+		t.Errorf("Subject() = %v, want %v", id.Subject(), a1)
+	}
+}
+
+func TestPairwiseTable(t *testing.T) {
+	table := secevsubid.NewPairwiseTable([]byte("secret"))
+	sub, err := table.Subject("user1", "a.example.com")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	opaque, _ := secevsubid.NewPairwiseOpaqueIdentifier("user1", "a.example.com", []byte("secret"))
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", sub)
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+
+	tests := []struct {
+		name     string
+		sectorId string
+		id       secevsubid.SubjectIdentifier
+		want     string
+		wantErr  error
+	}{
+		{name: "opaque", sectorId: "a.example.com", id: opaque, want: "user1"},
+		{name: "iss_sub", sectorId: "a.example.com", id: issSub, want: "user1"},
+		{name: "other sector", sectorId: "b.example.com", id: opaque, wantErr: secevsubid.ErrUnresolvedSubject},
+		{name: "not pairwise", sectorId: "a.example.com", id: email, wantErr: secevsubid.ErrUnresolvedSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.LookupIdentifier(tt.sectorId, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LookupIdentifier() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("LookupIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
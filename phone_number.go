@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // PhoneNumberIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Phone Number Identifier Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-phone-number-identifier-for
@@ -34,6 +39,32 @@ func (id *phoneNumberIdentifier) Validate() error {
 	return nil
 }
 
+func (id *phoneNumberIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *phoneNumberIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *phoneNumberIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *phoneNumberIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "phoneNumberIdentifier")
+}
+
+func (id *phoneNumberIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatPhoneNumber)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*phoneNumberIdentifier)
+	return nil
+}
+
 // NewPhoneNumberIdentifier creates new instance of PhoneNumberIdentifier.
 // The argument "phoneNumber" is required. If it's empty, this function returns error.
 func NewPhoneNumberIdentifier(phoneNumber string) (PhoneNumberIdentifier, error) {
@@ -0,0 +1,440 @@
+package secevsubid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SET error codes registered in "Security Event Token Error Codes" registry of RFC 8935 and used by poll-based delivery.
+// Reference: https://datatracker.ietf.org/doc/html/rfc8935#section-7.1
+const (
+	SETErrInvalidRequest       = "invalid_request"
+	SETErrInvalidKey           = "invalid_key"
+	SETErrInvalidIssuer        = "invalid_issuer"
+	SETErrInvalidAudience      = "invalid_audience"
+	SETErrAuthenticationFailed = "authentication_failed"
+	SETErrAccessDenied         = "access_denied"
+)
+
+// SETError is the error reported by the receiver for a Security Event Token it could not process.
+type SETError struct {
+	// Err is the error code.
+	Err string `json:"err"`
+	// Description is the human-readable description of the error.
+	Description string `json:"description"`
+}
+
+// PollRequest is the request body of poll-based Security Event Token delivery defined in RFC 8936.
+// Reference: https://datatracker.ietf.org/doc/html/rfc8936#section-2.4
+type PollRequest struct {
+	// MaxEvents is the maximum number of SETs the receiver wants. nil means that the transmitter decides.
+	MaxEvents *int `json:"maxEvents,omitempty"`
+	// ReturnImmediately indicates whether the transmitter should respond without waiting for new SETs.
+	ReturnImmediately bool `json:"returnImmediately,omitempty"`
+	// Ack is the list of jti values of SETs processed successfully.
+	Ack []string `json:"ack,omitempty"`
+	// SetErrs is the map of jti values and errors of SETs failed to process.
+	SetErrs map[string]SETError `json:"setErrs,omitempty"`
+}
+
+// PollResponse is the response body of poll-based Security Event Token delivery defined in RFC 8936.
+// Reference: https://datatracker.ietf.org/doc/html/rfc8936#section-2.5
+type PollResponse struct {
+	// Sets is the map of jti values and encoded SETs.
+	Sets map[string]string `json:"sets"`
+	// MoreAvailable indicates whether more SETs are waiting for delivery.
+	MoreAvailable bool `json:"moreAvailable,omitempty"`
+}
+
+// EventQueue holds encoded Security Event Tokens waiting for delivery.
+type EventQueue interface {
+	// Enqueue adds encoded SET with its jti value.
+	Enqueue(jti string, set string) error
+	// Fetch returns at most max SETs and whether more SETs are available.
+	// If wait is true and there is no SET, Fetch waits until any SET is enqueued or ctx is done.
+	Fetch(ctx context.Context, max int, wait bool) (map[string]string, bool, error)
+	// Ack removes the SET acknowledged by the receiver.
+	Ack(jti string) error
+	// Fail removes the SET the receiver failed to process and records the error.
+	Fail(jti string, e SETError) error
+}
+
+type queuedEvent struct {
+	jti         string
+	set         string
+	deliveredAt time.Time
+}
+
+// DefaultRedeliveryInterval is the interval after which MemoryEventQueue delivers unacknowledged SETs again by default.
+const DefaultRedeliveryInterval = 30 * time.Second
+
+// MemoryEventQueue is an in-memory implementation of EventQueue.
+// The zero value is an empty queue ready to use.
+type MemoryEventQueue struct {
+	// RedeliveryInterval is the interval after which unacknowledged SETs are delivered again.
+	// If zero, DefaultRedeliveryInterval is used. If negative, delivered SETs are never delivered again.
+	RedeliveryInterval time.Duration
+
+	mu     sync.Mutex
+	events []*queuedEvent
+	errs   map[string]SETError
+	notify chan struct{}
+}
+
+// NewMemoryEventQueue creates new instance of MemoryEventQueue.
+func NewMemoryEventQueue() *MemoryEventQueue {
+	q := &MemoryEventQueue{}
+	q.lazyInit()
+	return q
+}
+
+// lazyInit initializes internal fields of the zero value. The caller must hold q.mu.
+func (q *MemoryEventQueue) lazyInit() {
+	if q.errs == nil {
+		q.errs = make(map[string]SETError)
+	}
+	if q.notify == nil {
+		q.notify = make(chan struct{})
+	}
+}
+
+// Enqueue implements EventQueue.
+func (q *MemoryEventQueue) Enqueue(jti string, set string) error {
+	if jti == "" {
+		return ErrEmptyJwtId
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lazyInit()
+	for _, e := range q.events {
+		if e.jti == jti {
+			return fmt.Errorf("jti already enqueued: %s", jti)
+		}
+	}
+	q.events = append(q.events, &queuedEvent{jti: jti, set: set})
+	close(q.notify)
+	q.notify = make(chan struct{})
+	return nil
+}
+
+// Fetch implements EventQueue.
+func (q *MemoryEventQueue) Fetch(ctx context.Context, max int, wait bool) (map[string]string, bool, error) {
+	for {
+		q.mu.Lock()
+		q.lazyInit()
+		sets, more := q.take(max)
+		notify := q.notify
+		q.mu.Unlock()
+
+		if len(sets) > 0 || !wait || max == 0 {
+			return sets, more, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return sets, false, nil
+		case <-notify:
+		}
+	}
+}
+
+func (q *MemoryEventQueue) take(max int) (map[string]string, bool) {
+	now := time.Now()
+	sets := make(map[string]string)
+	for _, e := range q.events {
+		if !q.deliverable(e, now) {
+			continue
+		}
+		if len(sets) >= max {
+			return sets, true
+		}
+		e.deliveredAt = now
+		sets[e.jti] = e.set
+	}
+	return sets, false
+}
+
+func (q *MemoryEventQueue) deliverable(e *queuedEvent, now time.Time) bool {
+	if e.deliveredAt.IsZero() {
+		return true
+	}
+	interval := q.RedeliveryInterval
+	if interval == 0 {
+		interval = DefaultRedeliveryInterval
+	}
+	return interval > 0 && now.Sub(e.deliveredAt) >= interval
+}
+
+// Ack implements EventQueue.
+func (q *MemoryEventQueue) Ack(jti string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.remove(jti)
+	return nil
+}
+
+// Fail implements EventQueue.
+func (q *MemoryEventQueue) Fail(jti string, e SETError) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lazyInit()
+	q.remove(jti)
+	q.errs[jti] = e
+	return nil
+}
+
+func (q *MemoryEventQueue) remove(jti string) {
+	for i, e := range q.events {
+		if e.jti == jti {
+			q.events = append(q.events[:i], q.events[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of SETs not yet acknowledged.
+func (q *MemoryEventQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
+
+// Errors returns the errors reported by the receiver keyed by jti value.
+func (q *MemoryEventQueue) Errors() map[string]SETError {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	errs := make(map[string]SETError, len(q.errs))
+	for k, v := range q.errs {
+		errs[k] = v
+	}
+	return errs
+}
+
+// DefaultPollMaxEvents is the number of SETs PollHandler returns when neither the request nor the handler specifies it.
+const DefaultPollMaxEvents = 100
+
+// PollHandler is http.Handler serving the poll endpoint of RFC 8936 backed by EventQueue.
+// Authentication of the receiver is not handled, so wrap it with your own middleware.
+type PollHandler struct {
+	// Queue is the EventQueue holding SETs for the receiver.
+	Queue EventQueue
+	// MaxEvents is the number of SETs returned when the request does not specify "maxEvents".
+	// If zero or less, DefaultPollMaxEvents is used.
+	MaxEvents int
+	// Timeout is the maximum time to wait for new SETs when "returnImmediately" is false.
+	Timeout time.Duration
+}
+
+// NewPollHandler creates new instance of PollHandler.
+func NewPollHandler(queue EventQueue) *PollHandler {
+	return &PollHandler{
+		Queue:     queue,
+		MaxEvents: DefaultPollMaxEvents,
+		Timeout:   30 * time.Second,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *PollHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, SETErrInvalidRequest, "method not allowed")
+		return
+	}
+
+	var req PollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+		return
+	}
+
+	max := h.MaxEvents
+	if max <= 0 {
+		max = DefaultPollMaxEvents
+	}
+	if req.MaxEvents != nil {
+		if *req.MaxEvents < 0 {
+			writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, "negative maxEvents")
+			return
+		}
+		max = *req.MaxEvents
+	}
+
+	for _, jti := range req.Ack {
+		if err := h.Queue.Ack(jti); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, SETErrInvalidRequest, err.Error())
+			return
+		}
+	}
+	for jti, e := range req.SetErrs {
+		if err := h.Queue.Fail(jti, e); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, SETErrInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if !req.ReturnImmediately && h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	sets, more, err := h.Queue.Fetch(ctx, max, !req.ReturnImmediately)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, SETErrInvalidRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &PollResponse{Sets: sets, MoreAvailable: more})
+}
+
+// PollClient polls Security Event Tokens from the poll endpoint of RFC 8936.
+type PollClient struct {
+	// Endpoint is the URL of the poll endpoint.
+	Endpoint string
+	// HTTPClient is used for requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Header is added to each request, e.g. "Authorization".
+	Header http.Header
+	// Verifier verifies the signature of received SETs.
+	Verifier SETVerifier
+	// MaxEvents is the value of "maxEvents". If zero, it is omitted.
+	MaxEvents int
+	// ReturnImmediately is the value of "returnImmediately".
+	ReturnImmediately bool
+	// Interval is the time to sleep when no SET is returned in Run.
+	Interval time.Duration
+	// JTIStore detects redelivered SETs in Run using HandleOnce. Redelivered SETs are acknowledged without calling the handler.
+	// If nil, replays are not detected.
+	JTIStore JTIStore
+	// OnError is called in Run with the jti and the error of SETs left for redelivery, e.g. the handler failed.
+	// If nil, such errors are ignored.
+	OnError func(jti string, err error)
+}
+
+// NewPollClient creates new instance of PollClient.
+func NewPollClient(endpoint string, verifier SETVerifier) *PollClient {
+	return &PollClient{
+		Endpoint: endpoint,
+		Verifier: verifier,
+		Interval: 5 * time.Second,
+	}
+}
+
+// Poll sends the PollRequest and returns the PollResponse.
+func (c *PollClient) Poll(ctx context.Context, req *PollRequest) (*PollResponse, error) {
+	res := &PollResponse{}
+	if err := postJSON(ctx, c.HTTPClient, c.Endpoint, c.Header, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Run polls SETs repeatedly and calls the handler for each decoded SET until ctx is done.
+// SETs processed successfully are acknowledged and SETs failed to decode are reported as errors at the next poll.
+// SETs the handler failed to process are neither acknowledged nor reported, so that the transmitter delivers them again.
+// When ctx is done, pending acknowledgements are sent once more and ctx.Err() is returned.
+func (c *PollClient) Run(ctx context.Context, handler func(ctx context.Context, set *SecurityEventToken) error) error {
+	var acks []string
+	errs := make(map[string]SETError)
+	for {
+		req := &PollRequest{
+			ReturnImmediately: c.ReturnImmediately,
+			Ack:               acks,
+			SetErrs:           errs,
+		}
+		if c.MaxEvents > 0 {
+			max := c.MaxEvents
+			req.MaxEvents = &max
+		}
+
+		res, err := c.Poll(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.flush(acks, errs)
+				return ctx.Err()
+			}
+			return err
+		}
+
+		acks = nil
+		errs = make(map[string]SETError)
+		for jti, token := range res.Sets {
+			ack, e := c.process(ctx, token, handler)
+			if e != nil {
+				errs[jti] = *e
+				continue
+			}
+			if ack {
+				acks = append(acks, jti)
+			}
+		}
+
+		if ctx.Err() != nil {
+			c.flush(acks, errs)
+			return ctx.Err()
+		}
+
+		if len(res.Sets) == 0 && !res.MoreAvailable && c.ReturnImmediately {
+			select {
+			case <-ctx.Done():
+				c.flush(acks, errs)
+				return ctx.Err()
+			case <-time.After(c.Interval):
+			}
+		}
+	}
+}
+
+// process decodes the SET and calls the handler for it.
+// It returns true if the SET should be acknowledged, or SETError if the SET should be reported as an error.
+// If neither, e.g. the handler failed, the SET is left unacknowledged to be delivered again.
+func (c *PollClient) process(ctx context.Context, token string, handler func(ctx context.Context, set *SecurityEventToken) error) (bool, *SETError) {
+	set, err := DecodeSET(token, c.Verifier)
+	if err != nil {
+		if err == ErrInvalidSignature {
+			return false, &SETError{Err: SETErrInvalidKey, Description: err.Error()}
+		}
+		return false, &SETError{Err: SETErrInvalidRequest, Description: err.Error()}
+	}
+
+	if c.JTIStore != nil {
+		err = HandleOnce(ctx, c.JTIStore, set, handler)
+		if errors.Is(err, ErrReplayedSET) {
+			return true, nil
+		}
+	} else {
+		err = handler(ctx, set)
+	}
+	if err != nil {
+		if c.OnError != nil {
+			c.OnError(set.JwtId, err)
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (c *PollClient) flush(acks []string, errs map[string]SETError) {
+	if len(acks) == 0 && len(errs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	zero := 0
+	_, _ = c.Poll(ctx, &PollRequest{
+		MaxEvents:         &zero,
+		ReturnImmediately: true,
+		Ack:               acks,
+		SetErrs:           errs,
+	})
+}
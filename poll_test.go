@@ -0,0 +1,247 @@
+package secevsubid_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/pinzolo/secevsubid"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryEventQueue(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	for i := 0; i < 3; i++ {
+		if err := q.Enqueue(fmt.Sprintf("jti%d", i), fmt.Sprintf("set%d", i)); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	if err := q.Enqueue("jti0", "set0"); err == nil {
+		t.Error("error should be raised when jti is already enqueued")
+	}
+
+	sets, more, _ := q.Fetch(context.Background(), 2, false)
+	if len(sets) != 2 || !more {
+		t.Errorf("Fetch() got = %v, %v, want 2 sets and more", sets, more)
+	}
+	sets, more, _ = q.Fetch(context.Background(), 2, false)
+	if len(sets) != 1 || more {
+		t.Errorf("Fetch() got = %v, %v, want 1 set and no more", sets, more)
+	}
+	sets, _, _ = q.Fetch(context.Background(), 2, false)
+	if len(sets) != 0 {
+		t.Errorf("Fetch() got = %v, want delivered SETs not to be redelivered", sets)
+	}
+
+	_ = q.Ack("jti0")
+	_ = q.Fail("jti1", secevsubid.SETError{Err: secevsubid.SETErrInvalidRequest, Description: "unknown user"})
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", q.Len())
+	}
+	if e, ok := q.Errors()["jti1"]; !ok || e.Err != secevsubid.SETErrInvalidRequest {
+		t.Errorf("Errors() = %v, want invalid_request error for jti1", q.Errors())
+	}
+}
+
+func TestMemoryEventQueue_Redelivery(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	q.RedeliveryInterval = time.Millisecond
+	_ = q.Enqueue("jti", "set")
+
+	sets, _, _ := q.Fetch(context.Background(), 10, false)
+	if len(sets) != 1 {
+		t.Errorf("Fetch() got = %v, want 1 set", sets)
+	}
+	time.Sleep(2 * time.Millisecond)
+	sets, _, _ = q.Fetch(context.Background(), 10, false)
+	if len(sets) != 1 {
+		t.Errorf("Fetch() got = %v, want unacknowledged set to be redelivered", sets)
+	}
+}
+
+func TestMemoryEventQueue_RedeliveryDisabled(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	q.RedeliveryInterval = -1
+	_ = q.Enqueue("jti", "set")
+
+	_, _, _ = q.Fetch(context.Background(), 10, false)
+	time.Sleep(2 * time.Millisecond)
+	if sets, _, _ := q.Fetch(context.Background(), 10, false); len(sets) != 0 {
+		t.Errorf("Fetch() got = %v, want no redelivery", sets)
+	}
+}
+
+func TestMemoryEventQueue_FetchWait(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Enqueue("jti", "set")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	sets, _, err := q.Fetch(ctx, 10, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sets["jti"] != "set" {
+		t.Errorf("Fetch() got = %v, want enqueued set", sets)
+	}
+}
+
+func TestMemoryEventQueue_ZeroValue(t *testing.T) {
+	var q secevsubid.MemoryEventQueue
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Enqueue("jti1", "set1")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	sets, _, err := q.Fetch(ctx, 10, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sets["jti1"] != "set1" {
+		t.Errorf("Fetch() got = %v, want enqueued set", sets)
+	}
+
+	_ = q.Enqueue("jti2", "set2")
+	if err = q.Ack("jti1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = q.Fail("jti2", secevsubid.SETError{Err: secevsubid.SETErrInvalidRequest}); err != nil {
+		t.Error(err)
+		return
+	}
+	if q.Len() != 0 || len(q.Errors()) != 1 {
+		t.Errorf("Len() = %d, Errors() = %v, want empty queue and an error", q.Len(), q.Errors())
+	}
+}
+
+func TestPollHandler(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	_ = q.Enqueue("jti1", "set1")
+	_ = q.Enqueue("jti2", "set2")
+	srv := httptest.NewServer(secevsubid.NewPollHandler(q))
+	defer srv.Close()
+
+	c := secevsubid.NewPollClient(srv.URL, secevsubid.HMACKey("secret"))
+	one := 1
+	res, err := c.Poll(context.Background(), &secevsubid.PollRequest{MaxEvents: &one, ReturnImmediately: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(res.Sets) != 1 || !res.MoreAvailable {
+		t.Errorf("Poll() got = %v, want 1 set and more available", res)
+	}
+
+	var acked string
+	for jti := range res.Sets {
+		acked = jti
+	}
+	zero := 0
+	res, err = c.Poll(context.Background(), &secevsubid.PollRequest{MaxEvents: &zero, ReturnImmediately: true, Ack: []string{acked}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(res.Sets) != 0 {
+		t.Errorf("Poll() got = %v, want no sets when maxEvents is 0", res)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 after ack", q.Len())
+	}
+}
+
+func TestPollHandlerWithInvalidRequest(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	_ = q.Enqueue("jti1", "set1")
+	srv := httptest.NewServer(secevsubid.NewPollHandler(q))
+	defer srv.Close()
+
+	c := secevsubid.NewPollClient(srv.URL, secevsubid.HMACKey("secret"))
+	minus := -1
+	if _, err := c.Poll(context.Background(), &secevsubid.PollRequest{MaxEvents: &minus, Ack: []string{"jti1"}}); err == nil {
+		t.Error("error should be raised when maxEvents is negative")
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want rejected request not to change the queue", q.Len())
+	}
+}
+
+func TestPollHandler_ZeroValue(t *testing.T) {
+	q := secevsubid.NewMemoryEventQueue()
+	_ = q.Enqueue("jti1", "set1")
+	srv := httptest.NewServer(&secevsubid.PollHandler{Queue: q})
+	defer srv.Close()
+
+	c := secevsubid.NewPollClient(srv.URL, secevsubid.HMACKey("secret"))
+	res, err := c.Poll(context.Background(), &secevsubid.PollRequest{ReturnImmediately: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res.Sets["jti1"] != "set1" || res.MoreAvailable {
+		t.Errorf("Poll() = %v, want the enqueued set", res)
+	}
+}
+
+func TestPollClient_Run(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	q := secevsubid.NewMemoryEventQueue()
+	q.RedeliveryInterval = 10 * time.Millisecond
+	valid, _ := secevsubid.EncodeSET(newTestSET(t, "valid"), key)
+	rejected, _ := secevsubid.EncodeSET(newTestSET(t, "rejected"), key)
+	forged, _ := secevsubid.EncodeSET(newTestSET(t, "forged"), secevsubid.HMACKey("other"))
+	_ = q.Enqueue("valid", valid)
+	_ = q.Enqueue("rejected", rejected)
+	_ = q.Enqueue("forged", forged)
+
+	h := secevsubid.NewPollHandler(q)
+	h.Timeout = 20 * time.Millisecond
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var mu sync.Mutex
+	handled := make(map[string]int)
+	c := secevsubid.NewPollClient(srv.URL, key)
+	c.Interval = 10 * time.Millisecond
+	err := c.Run(ctx, func(ctx context.Context, set *secevsubid.SecurityEventToken) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled[set.JwtId]++
+		if set.JwtId == "rejected" && handled[set.JwtId] == 1 {
+			return fmt.Errorf("unknown subject")
+		}
+		if handled["valid"] == 1 && handled["rejected"] == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+
+	if handled["valid"] != 1 || handled["rejected"] != 2 || handled["forged"] != 0 {
+		t.Errorf("handled = %v, want rejected SET to be redelivered and handled again", handled)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d, want all SETs to be acknowledged or failed", q.Len())
+	}
+	errs := q.Errors()
+	if _, ok := errs["rejected"]; ok {
+		t.Errorf("Errors() = %v, want rejected SET not to be reported as error", errs)
+	}
+	if errs["forged"].Err != secevsubid.SETErrInvalidKey {
+		t.Errorf("Errors() = %v, want invalid_key for forged", errs)
+	}
+}
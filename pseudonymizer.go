@@ -0,0 +1,160 @@
+package secevsubid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Pseudonymizer converts SubjectIdentifier to OpaqueIdentifier by HMAC-SHA256 over its canonical form.
+// The output is deterministic, so the same subject is always converted to the same OpaqueIdentifier with the same key.
+// The id of the output is "<key ID>.<base64url encoded MAC>", so that pseudonyms generated before key rotation remain verifiable.
+// The zero value has no keys, so call Rotate before use.
+type Pseudonymizer struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+// NewPseudonymizer creates new instance of Pseudonymizer using the key as the current key.
+func NewPseudonymizer(kid string, key []byte) (*Pseudonymizer, error) {
+	p := &Pseudonymizer{}
+	if err := p.Rotate(kid, key); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// AddKey registers the key for verifying pseudonyms generated with it. The current key is not changed.
+// The key ID must not be empty and must not contain ".".
+func (p *Pseudonymizer) AddKey(kid string, key []byte) error {
+	if kid == "" || strings.Contains(kid, ".") || len(key) == 0 {
+		return fmt.Errorf("%w: kid = %q", ErrInvalidKey, kid)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys == nil {
+		p.keys = make(map[string][]byte)
+	}
+	p.keys[kid] = append([]byte(nil), key...)
+	return nil
+}
+
+// Rotate registers the key and uses it as the current key.
+func (p *Pseudonymizer) Rotate(kid string, key []byte) error {
+	if err := p.AddKey(kid, key); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = kid
+	return nil
+}
+
+// KeyId returns the ID of the current key.
+func (p *Pseudonymizer) KeyId() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current
+}
+
+// Pseudonymize converts the identifier to OpaqueIdentifier with the current key.
+func (p *Pseudonymizer) Pseudonymize(id SubjectIdentifier) (OpaqueIdentifier, error) {
+	return p.PseudonymizeWithKey(p.KeyId(), id)
+}
+
+// PseudonymizeWithKey converts the identifier to OpaqueIdentifier with the key of the key ID.
+func (p *Pseudonymizer) PseudonymizeWithKey(kid string, id SubjectIdentifier) (OpaqueIdentifier, error) {
+	mac, err := p.mac(kid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOpaqueIdentifier(kid + "." + base64.RawURLEncoding.EncodeToString(mac))
+}
+
+// Matches returns whether the pseudonym is generated from the identifier with any registered key.
+func (p *Pseudonymizer) Matches(pseudonym OpaqueIdentifier, id SubjectIdentifier) bool {
+	kid, encoded, ok := strings.Cut(pseudonym.Id(), ".")
+	if !ok {
+		return false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+
+	want, err := p.mac(kid, id)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, want)
+}
+
+func (p *Pseudonymizer) mac(kid string, id SubjectIdentifier) ([]byte, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyId, kid)
+	}
+
+	c, err := canonicalForm(id)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(c))
+	return h.Sum(nil), nil
+}
+
+// canonicalForm returns the string which is the same for identifiers with the same content.
+// Members of AliasesIdentifier are sorted, so that the order of members does not matter.
+func canonicalForm(id SubjectIdentifier) (string, error) {
+	if id == nil {
+		return "", ErrNoSubject
+	}
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		keys := make([]string, 0, len(v.Identifiers()))
+		for _, m := range v.Identifiers() {
+			k, err := identifierKey(m)
+			if err != nil {
+				return "", err
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return string(FormatAliases) + ":[" + strings.Join(keys, ",") + "]", nil
+	case ComplexIdentifier:
+		members := v.Members()
+		names := make([]string, 0, len(members))
+		for n := range members {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		parts := make([]string, len(names))
+		for i, n := range names {
+			c, err := canonicalForm(members[n])
+			if err != nil {
+				return "", err
+			}
+			parts[i] = n + "=" + c
+		}
+		return string(FormatComplex) + ":{" + strings.Join(parts, ",") + "}", nil
+	}
+
+	return identifierKey(id)
+}
@@ -0,0 +1,104 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizer_Pseudonymize(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	upperEmail, _ := secevsubid.NewEmailIdentifier("user@EXAMPLE.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, phone)
+	reversed, _ := secevsubid.NewAliasesIdentifier(phone, email)
+
+	p, err := secevsubid.NewPseudonymizer("k1", []byte("secret1"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name     string
+		a        secevsubid.SubjectIdentifier
+		b        secevsubid.SubjectIdentifier
+		wantSame bool
+	}{
+		{name: "same identifier", a: email, b: email, wantSame: true},
+		{name: "email domain case", a: email, b: upperEmail, wantSame: true},
+		{name: "aliases order", a: aliases, b: reversed, wantSame: true},
+		{name: "different identifiers", a: email, b: phone, wantSame: false},
+		{name: "aliases and member", a: aliases, b: email, wantSame: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := p.Pseudonymize(tt.a)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			b, err := p.Pseudonymize(tt.b)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if (a.Id() == b.Id()) != tt.wantSame {
+				t.Errorf("Pseudonymize() = %v and %v, wantSame %v", a.Id(), b.Id(), tt.wantSame)
+			}
+			if !strings.HasPrefix(a.Id(), "k1.") {
+				t.Errorf("Pseudonymize() = %v, want k1 prefixed opaque value", a.Id())
+			}
+		})
+	}
+}
+
+func TestPseudonymizer_Rotate(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	p, _ := secevsubid.NewPseudonymizer("k1", []byte("secret1"))
+	old, _ := p.Pseudonymize(email)
+
+	if err := p.Rotate("k2", []byte("secret2")); err != nil {
+		t.Error(err)
+		return
+	}
+	current, _ := p.Pseudonymize(email)
+	if current.Id() == old.Id() || !strings.HasPrefix(current.Id(), "k2.") {
+		t.Errorf("Pseudonymize() = %v after rotation, old = %v", current.Id(), old.Id())
+	}
+	if !p.Matches(old, email) || !p.Matches(current, email) {
+		t.Errorf("Matches() = false, want true for both keys")
+	}
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	if p.Matches(current, phone) {
+		t.Errorf("Matches() = true, want false for other identifier")
+	}
+
+	if _, err := p.PseudonymizeWithKey("k3", email); !errors.Is(err, secevsubid.ErrUnknownKeyId) {
+		t.Errorf("PseudonymizeWithKey() error = %v, wantErr %v", err, secevsubid.ErrUnknownKeyId)
+	}
+	if err := p.AddKey("k.3", []byte("secret3")); !errors.Is(err, secevsubid.ErrInvalidKey) {
+		t.Errorf("AddKey() error = %v, wantErr %v", err, secevsubid.ErrInvalidKey)
+	}
+}
+
+func TestPseudonymizer_ZeroValue(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	p := &secevsubid.Pseudonymizer{}
+	if _, err := p.Pseudonymize(email); !errors.Is(err, secevsubid.ErrUnknownKeyId) {
+		t.Errorf("Pseudonymize() error = %v, wantErr %v", err, secevsubid.ErrUnknownKeyId)
+	}
+	if err := p.Rotate("k1", []byte("secret")); err != nil {
+		t.Error(err)
+		return
+	}
+	pseudonym, err := p.Pseudonymize(email)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !p.Matches(pseudonym, email) {
+		t.Errorf("Matches() = false, want true")
+	}
+}
@@ -0,0 +1,214 @@
+package secevsubid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resolver maps SubjectIdentifier to the ID of local account.
+type Resolver interface {
+	// Resolve returns the account ID the subject is mapped to.
+	// If the subject is not mapped, it returns ErrUnresolvedSubject.
+	// If the subject is mapped to different accounts, it returns ResolveConflictError.
+	Resolve(ctx context.Context, subject SubjectIdentifier) (string, error)
+}
+
+// ResolveConflictError is error raised when members of AliasesIdentifier are mapped to different accounts.
+type ResolveConflictError struct {
+	// Subjects holds the members of the subject keyed by the account ID they are mapped to.
+	Subjects map[string][]SubjectIdentifier
+}
+
+// Accounts returns sorted account IDs in conflict.
+func (e *ResolveConflictError) Accounts() []string {
+	ids := make([]string, 0, len(e.Subjects))
+	for id := range e.Subjects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Error implements error.
+func (e *ResolveConflictError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrResolveConflict, strings.Join(e.Accounts(), ", "))
+}
+
+// Unwrap returns ErrResolveConflict.
+func (e *ResolveConflictError) Unwrap() error {
+	return ErrResolveConflict
+}
+
+// MemoryResolver is an in-memory implementation of Resolver indexed by the canonical form of identifiers.
+// AliasesIdentifier is resolved via its members, and ComplexIdentifier is resolved via its "user" member.
+// Email addresses are matched with their domain case-insensitively.
+// The zero value is an empty resolver ready to use.
+type MemoryResolver struct {
+	mu    sync.RWMutex
+	index map[string]string
+}
+
+// NewMemoryResolver creates new instance of MemoryResolver.
+func NewMemoryResolver() *MemoryResolver {
+	return &MemoryResolver{index: make(map[string]string)}
+}
+
+// Add maps the subjects to the account.
+// If any of them is already mapped to another account, this method maps none of them and returns an error.
+func (r *MemoryResolver) Add(accountId string, subjects ...SubjectIdentifier) error {
+	if accountId == "" {
+		return ErrEmptyId
+	}
+
+	var keys []string
+	for _, s := range subjects {
+		ks, err := resolvableKeys(s)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, ks...)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range keys {
+		if id, ok := r.index[k]; ok && id != accountId {
+			return fmt.Errorf("%w: %s is already mapped to %s", ErrResolveConflict, k, id)
+		}
+	}
+	if r.index == nil {
+		r.index = make(map[string]string)
+	}
+	for _, k := range keys {
+		r.index[k] = accountId
+	}
+
+	return nil
+}
+
+// Remove removes mappings of the subjects.
+func (r *MemoryResolver) Remove(subjects ...SubjectIdentifier) error {
+	var keys []string
+	for _, s := range subjects {
+		ks, err := resolvableKeys(s)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, ks...)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range keys {
+		delete(r.index, k)
+	}
+
+	return nil
+}
+
+// Resolve implements Resolver.
+func (r *MemoryResolver) Resolve(ctx context.Context, subject SubjectIdentifier) (string, error) {
+	if subject == nil {
+		return "", ErrNoSubject
+	}
+
+	ids := resolvableIdentifiers(subject)
+	if len(ids) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnresolvedSubject, subject.Format())
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	found := make(map[string][]SubjectIdentifier)
+	for _, id := range ids {
+		k, err := identifierKey(id)
+		if err != nil {
+			return "", err
+		}
+		if a, ok := r.index[k]; ok {
+			found[a] = append(found[a], id)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrUnresolvedSubject, subject.Format())
+	case 1:
+		for a := range found {
+			return a, nil
+		}
+	}
+
+	return "", &ResolveConflictError{Subjects: found}
+}
+
+// resolvableIdentifiers returns identifiers used to resolve the subject.
+// AliasesIdentifier is expanded to its members and ComplexIdentifier is replaced with its "user" member.
+func resolvableIdentifiers(subject SubjectIdentifier) []SubjectIdentifier {
+	switch v := subject.(type) {
+	case AliasesIdentifier:
+		return v.Identifiers()
+	case ComplexIdentifier:
+		if u := v.User(); u != nil {
+			return resolvableIdentifiers(u)
+		}
+		return nil
+	}
+
+	return []SubjectIdentifier{subject}
+}
+
+func resolvableKeys(subject SubjectIdentifier) ([]string, error) {
+	if subject == nil {
+		return nil, ErrNoSubject
+	}
+
+	ids := resolvableIdentifiers(subject)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: complex subject without user member", ErrUnresolvedSubject)
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		k, err := identifierKey(id)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// identifierKey returns the canonical form of the identifier, which is the same for identifiers with the same content.
+// The domain of email addresses is lowercased. AliasesIdentifier and ComplexIdentifier have no canonical form.
+func identifierKey(id SubjectIdentifier) (string, error) {
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		return "", ErrNestedAliases
+	case ComplexIdentifier:
+		return "", ErrNestedComplex
+	case EmailIdentifier:
+		return string(FormatEmail) + ":" + normalizeEmail(v.Email()), nil
+	}
+
+	b, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+
+	return string(id.Format()) + ":" + string(b), nil
+}
+
+func normalizeEmail(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return email
+	}
+
+	return email[:i] + strings.ToLower(email[i:])
+}
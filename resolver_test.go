@@ -0,0 +1,129 @@
+package secevsubid_test
+
+import (
+	"context"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryResolver_Resolve(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	upperEmail, _ := secevsubid.NewEmailIdentifier("user@EXAMPLE.com")
+	otherEmail, _ := secevsubid.NewEmailIdentifier("other@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	otherIssSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "999")
+	unknown, _ := secevsubid.NewOpaqueIdentifier("unknown")
+	aliases, _ := secevsubid.NewAliasesIdentifier(unknown, issSub)
+	conflicted, _ := secevsubid.NewAliasesIdentifier(email, otherIssSub)
+	unknownAliases, _ := secevsubid.NewAliasesIdentifier(unknown, otherEmail)
+	device, _ := secevsubid.NewOpaqueIdentifier("device")
+	complexUser, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: phone, Device: device})
+	complexDevice, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{Device: device})
+
+	r := secevsubid.NewMemoryResolver()
+	if err := r.Add("account1", email, issSub); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := r.Add("account2", otherIssSub, phone); err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		name    string
+		subject secevsubid.SubjectIdentifier
+		want    string
+		wantErr error
+	}{
+		{name: "email", subject: email, want: "account1"},
+		{name: "email with uppercase domain", subject: upperEmail, want: "account1"},
+		{name: "iss_sub", subject: otherIssSub, want: "account2"},
+		{name: "aliases", subject: aliases, want: "account1"},
+		{name: "complex", subject: complexUser, want: "account2"},
+		{name: "unknown", subject: unknown, wantErr: secevsubid.ErrUnresolvedSubject},
+		{name: "unknown aliases", subject: unknownAliases, wantErr: secevsubid.ErrUnresolvedSubject},
+		{name: "complex without user", subject: complexDevice, wantErr: secevsubid.ErrUnresolvedSubject},
+		{name: "conflict", subject: conflicted, wantErr: secevsubid.ErrResolveConflict},
+		{name: "nil", subject: nil, wantErr: secevsubid.ErrNoSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tt.subject)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryResolver_ResolveConflict(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub)
+
+	r := secevsubid.NewMemoryResolver()
+	_ = r.Add("account1", email)
+	_ = r.Add("account2", issSub)
+
+	_, err := r.Resolve(context.Background(), aliases)
+	var ce *secevsubid.ResolveConflictError
+	if !errors.As(err, &ce) {
+		t.Errorf("Resolve() error = %v, want ResolveConflictError", err)
+		return
+	}
+	if want := []string{"account1", "account2"}; !reflect.DeepEqual(ce.Accounts(), want) {
+		t.Errorf("Accounts() = %v, want %v", ce.Accounts(), want)
+	}
+}
+
+func TestMemoryResolver_Add(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub)
+
+	r := secevsubid.NewMemoryResolver()
+	if err := r.Add("account1", aliases); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := r.Add("account2", issSub); !errors.Is(err, secevsubid.ErrResolveConflict) {
+		t.Errorf("Add() error = %v, wantErr %v", err, secevsubid.ErrResolveConflict)
+	}
+	if err := r.Add("", email); err != secevsubid.ErrEmptyId {
+		t.Errorf("Add() error = %v, wantErr %v", err, secevsubid.ErrEmptyId)
+	}
+
+	if err := r.Remove(email); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := r.Resolve(context.Background(), email); !errors.Is(err, secevsubid.ErrUnresolvedSubject) {
+		t.Errorf("Resolve() error = %v, wantErr %v", err, secevsubid.ErrUnresolvedSubject)
+	}
+	if got, _ := r.Resolve(context.Background(), issSub); got != "account1" {
+		t.Errorf("Resolve() = %v, want account1", got)
+	}
+}
+
+func TestMemoryResolver_ZeroValue(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	r := &secevsubid.MemoryResolver{}
+	if _, err := r.Resolve(context.Background(), email); !errors.Is(err, secevsubid.ErrUnresolvedSubject) {
+		t.Errorf("Resolve() error = %v, wantErr %v", err, secevsubid.ErrUnresolvedSubject)
+	}
+	if err := r.Add("account1", email); err != nil {
+		t.Error(err)
+		return
+	}
+	if got, err := r.Resolve(context.Background(), email); err != nil || got != "account1" {
+		t.Errorf("Resolve() = %v, %v, want account1", got, err)
+	}
+}
@@ -0,0 +1,465 @@
+package secevsubid
+
+import (
+	"strings"
+)
+
+// Event type URIs defined in the OpenID Risk Incident Sharing and Coordination (RISC) Profile specification.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html
+const (
+	// EventTypeAccountCredentialChangeRequired is the event type URI of "Account Credential Change Required" event.
+	EventTypeAccountCredentialChangeRequired = "https://schemas.openid.net/secevent/risc/event-type/account-credential-change-required"
+	// EventTypeAccountPurged is the event type URI of "Account Purged" event.
+	EventTypeAccountPurged = "https://schemas.openid.net/secevent/risc/event-type/account-purged"
+	// EventTypeAccountDisabled is the event type URI of "Account Disabled" event.
+	EventTypeAccountDisabled = "https://schemas.openid.net/secevent/risc/event-type/account-disabled"
+	// EventTypeAccountEnabled is the event type URI of "Account Enabled" event.
+	EventTypeAccountEnabled = "https://schemas.openid.net/secevent/risc/event-type/account-enabled"
+	// EventTypeIdentifierChanged is the event type URI of "Identifier Changed" event.
+	EventTypeIdentifierChanged = "https://schemas.openid.net/secevent/risc/event-type/identifier-changed"
+	// EventTypeIdentifierRecycled is the event type URI of "Identifier Recycled" event.
+	EventTypeIdentifierRecycled = "https://schemas.openid.net/secevent/risc/event-type/identifier-recycled"
+	// EventTypeCredentialCompromise is the event type URI of "Credential Compromise" event.
+	EventTypeCredentialCompromise = "https://schemas.openid.net/secevent/risc/event-type/credential-compromise"
+	// EventTypeOptIn is the event type URI of "Opt In" event.
+	EventTypeOptIn = "https://schemas.openid.net/secevent/risc/event-type/opt-in"
+	// EventTypeOptOutInitiated is the event type URI of "Opt Out Initiated" event.
+	EventTypeOptOutInitiated = "https://schemas.openid.net/secevent/risc/event-type/opt-out-initiated"
+	// EventTypeOptOutCancelled is the event type URI of "Opt Out Cancelled" event.
+	EventTypeOptOutCancelled = "https://schemas.openid.net/secevent/risc/event-type/opt-out-cancelled"
+	// EventTypeOptOutEffective is the event type URI of "Opt Out Effective" event.
+	EventTypeOptOutEffective = "https://schemas.openid.net/secevent/risc/event-type/opt-out-effective"
+	// EventTypeRecoveryActivated is the event type URI of "Recovery Activated" event.
+	EventTypeRecoveryActivated = "https://schemas.openid.net/secevent/risc/event-type/recovery-activated"
+	// EventTypeRecoveryInformationChanged is the event type URI of "Recovery Information Changed" event.
+	EventTypeRecoveryInformationChanged = "https://schemas.openid.net/secevent/risc/event-type/recovery-information-changed"
+)
+
+var (
+	riscAccountSubjectFormats    = []Format{FormatIssuerSubject, FormatEmail, FormatPhoneNumber, FormatAccount, FormatOpaque, FormatDid, FormatUri}
+	riscIdentifierSubjectFormats = []Format{FormatEmail, FormatPhoneNumber}
+)
+
+// AccountCredentialChangeRequiredEvent is the "Account Credential Change Required" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.1
+type AccountCredentialChangeRequiredEvent struct{}
+
+// EventType implements Event.
+func (e *AccountCredentialChangeRequiredEvent) EventType() string {
+	return EventTypeAccountCredentialChangeRequired
+}
+
+// SubjectFormats implements Event.
+func (e *AccountCredentialChangeRequiredEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *AccountCredentialChangeRequiredEvent) Validate() error {
+	return nil
+}
+
+// NewAccountCredentialChangeRequiredEvent creates new instance of AccountCredentialChangeRequiredEvent.
+func NewAccountCredentialChangeRequiredEvent() *AccountCredentialChangeRequiredEvent {
+	return &AccountCredentialChangeRequiredEvent{}
+}
+
+// AccountPurgedEvent is the "Account Purged" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.2
+type AccountPurgedEvent struct{}
+
+// EventType implements Event.
+func (e *AccountPurgedEvent) EventType() string {
+	return EventTypeAccountPurged
+}
+
+// SubjectFormats implements Event.
+func (e *AccountPurgedEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *AccountPurgedEvent) Validate() error {
+	return nil
+}
+
+// NewAccountPurgedEvent creates new instance of AccountPurgedEvent.
+func NewAccountPurgedEvent() *AccountPurgedEvent {
+	return &AccountPurgedEvent{}
+}
+
+// AccountDisabledReason is the value of "reason" member of "Account Disabled" event.
+type AccountDisabledReason string
+
+const (
+	// AccountDisabledReasonHijacking means that the account was disabled because it was hijacked.
+	AccountDisabledReasonHijacking = AccountDisabledReason("hijacking")
+	// AccountDisabledReasonBulkAccount means that the account was disabled because it was created in bulk.
+	AccountDisabledReasonBulkAccount = AccountDisabledReason("bulk-account")
+)
+
+// AccountDisabledEvent is the "Account Disabled" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.3
+type AccountDisabledEvent struct {
+	// Reason is the reason why the account was disabled.
+	Reason AccountDisabledReason `json:"reason,omitempty"`
+}
+
+// EventType implements Event.
+func (e *AccountDisabledEvent) EventType() string {
+	return EventTypeAccountDisabled
+}
+
+// SubjectFormats implements Event.
+func (e *AccountDisabledEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *AccountDisabledEvent) Validate() error {
+	switch e.Reason {
+	case "", AccountDisabledReasonHijacking, AccountDisabledReasonBulkAccount:
+		return nil
+	}
+
+	return invalidEventMember("reason", string(e.Reason))
+}
+
+// NewAccountDisabledEvent creates new instance of AccountDisabledEvent.
+// The argument "reason" is optional. If it's neither empty nor known reason, this function returns error.
+func NewAccountDisabledEvent(reason AccountDisabledReason) (*AccountDisabledEvent, error) {
+	e := &AccountDisabledEvent{Reason: reason}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// AccountEnabledEvent is the "Account Enabled" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.4
+type AccountEnabledEvent struct{}
+
+// EventType implements Event.
+func (e *AccountEnabledEvent) EventType() string {
+	return EventTypeAccountEnabled
+}
+
+// SubjectFormats implements Event.
+func (e *AccountEnabledEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *AccountEnabledEvent) Validate() error {
+	return nil
+}
+
+// NewAccountEnabledEvent creates new instance of AccountEnabledEvent.
+func NewAccountEnabledEvent() *AccountEnabledEvent {
+	return &AccountEnabledEvent{}
+}
+
+// IdentifierChangedEvent is the "Identifier Changed" event of RISC.
+// The subject is the old value of the identifier.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.5
+type IdentifierChangedEvent struct {
+	// NewValue is the new value of the identifier.
+	NewValue string `json:"new-value,omitempty"`
+}
+
+// EventType implements Event.
+func (e *IdentifierChangedEvent) EventType() string {
+	return EventTypeIdentifierChanged
+}
+
+// SubjectFormats implements Event.
+func (e *IdentifierChangedEvent) SubjectFormats() []Format {
+	return riscIdentifierSubjectFormats
+}
+
+// Validate implements Event.
+func (e *IdentifierChangedEvent) Validate() error {
+	return nil
+}
+
+// NewSubject returns SubjectIdentifier of the new value in the same format as the subject.
+// For AliasesIdentifier, the format of its email or phone_number member is used.
+// If it has both, the new value is treated as email when it contains "@", otherwise as phone number.
+// If the new value is empty, this method returns nil.
+func (e *IdentifierChangedEvent) NewSubject(subject SubjectIdentifier) (SubjectIdentifier, error) {
+	if subject == nil {
+		return nil, ErrNoSubject
+	}
+	if e.NewValue == "" {
+		return nil, nil
+	}
+
+	f := subject.Format()
+	if a, ok := subject.(AliasesIdentifier); ok {
+		f = identifierChangedFormat(a, e.NewValue)
+	}
+
+	switch f {
+	case FormatEmail:
+		return NewEmailIdentifier(e.NewValue)
+	case FormatPhoneNumber:
+		return NewPhoneNumberIdentifier(e.NewValue)
+	}
+
+	return nil, ErrSubjectFormatNotAllowed
+}
+
+func identifierChangedFormat(id AliasesIdentifier, newValue string) Format {
+	var hasEmail, hasPhone bool
+	for _, m := range id.Identifiers() {
+		switch m.Format() {
+		case FormatEmail:
+			hasEmail = true
+		case FormatPhoneNumber:
+			hasPhone = true
+		}
+	}
+
+	switch {
+	case hasEmail && hasPhone:
+		if strings.Contains(newValue, "@") {
+			return FormatEmail
+		}
+		return FormatPhoneNumber
+	case hasEmail:
+		return FormatEmail
+	case hasPhone:
+		return FormatPhoneNumber
+	}
+
+	return FormatAliases
+}
+
+// NewIdentifierChangedEvent creates new instance of IdentifierChangedEvent from the new identifier.
+// The argument "newValue" is optional. If it's not nil, it must be in Email or Phone Number Identifier Format.
+func NewIdentifierChangedEvent(newValue SubjectIdentifier) (*IdentifierChangedEvent, error) {
+	if newValue == nil {
+		return &IdentifierChangedEvent{}, nil
+	}
+
+	switch v := newValue.(type) {
+	case EmailIdentifier:
+		return &IdentifierChangedEvent{NewValue: v.Email()}, nil
+	case PhoneNumberIdentifier:
+		return &IdentifierChangedEvent{NewValue: v.PhoneNumber()}, nil
+	}
+
+	return nil, ErrSubjectFormatNotAllowed
+}
+
+// IdentifierRecycledEvent is the "Identifier Recycled" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.6
+type IdentifierRecycledEvent struct{}
+
+// EventType implements Event.
+func (e *IdentifierRecycledEvent) EventType() string {
+	return EventTypeIdentifierRecycled
+}
+
+// SubjectFormats implements Event.
+func (e *IdentifierRecycledEvent) SubjectFormats() []Format {
+	return riscIdentifierSubjectFormats
+}
+
+// Validate implements Event.
+func (e *IdentifierRecycledEvent) Validate() error {
+	return nil
+}
+
+// NewIdentifierRecycledEvent creates new instance of IdentifierRecycledEvent.
+func NewIdentifierRecycledEvent() *IdentifierRecycledEvent {
+	return &IdentifierRecycledEvent{}
+}
+
+// CredentialCompromiseEvent is the "Credential Compromise" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.7
+type CredentialCompromiseEvent struct {
+	// CredentialType is the type of the compromised credential, e.g. "password".
+	CredentialType string `json:"credential_type"`
+	// EventTimestamp is the time at which the compromise was detected in seconds since the epoch.
+	EventTimestamp int64 `json:"event_timestamp,omitempty"`
+	// ReasonAdmin is the reason for administrators keyed by language tag.
+	ReasonAdmin map[string]string `json:"reason_admin,omitempty"`
+	// ReasonUser is the reason for end-users keyed by language tag.
+	ReasonUser map[string]string `json:"reason_user,omitempty"`
+}
+
+// EventType implements Event.
+func (e *CredentialCompromiseEvent) EventType() string {
+	return EventTypeCredentialCompromise
+}
+
+// SubjectFormats implements Event.
+func (e *CredentialCompromiseEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *CredentialCompromiseEvent) Validate() error {
+	if e.CredentialType == "" {
+		return missingEventMember("credential_type")
+	}
+
+	return nil
+}
+
+// NewCredentialCompromiseEvent creates new instance of CredentialCompromiseEvent.
+// The argument "credentialType" is required. If it's empty, this function returns error.
+func NewCredentialCompromiseEvent(credentialType string) (*CredentialCompromiseEvent, error) {
+	e := &CredentialCompromiseEvent{CredentialType: credentialType}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// OptInEvent is the "Opt In" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.8.1
+type OptInEvent struct{}
+
+// EventType implements Event.
+func (e *OptInEvent) EventType() string {
+	return EventTypeOptIn
+}
+
+// SubjectFormats implements Event.
+func (e *OptInEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *OptInEvent) Validate() error {
+	return nil
+}
+
+// NewOptInEvent creates new instance of OptInEvent.
+func NewOptInEvent() *OptInEvent {
+	return &OptInEvent{}
+}
+
+// OptOutInitiatedEvent is the "Opt Out Initiated" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.8.2
+type OptOutInitiatedEvent struct{}
+
+// EventType implements Event.
+func (e *OptOutInitiatedEvent) EventType() string {
+	return EventTypeOptOutInitiated
+}
+
+// SubjectFormats implements Event.
+func (e *OptOutInitiatedEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *OptOutInitiatedEvent) Validate() error {
+	return nil
+}
+
+// NewOptOutInitiatedEvent creates new instance of OptOutInitiatedEvent.
+func NewOptOutInitiatedEvent() *OptOutInitiatedEvent {
+	return &OptOutInitiatedEvent{}
+}
+
+// OptOutCancelledEvent is the "Opt Out Cancelled" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.8.3
+type OptOutCancelledEvent struct{}
+
+// EventType implements Event.
+func (e *OptOutCancelledEvent) EventType() string {
+	return EventTypeOptOutCancelled
+}
+
+// SubjectFormats implements Event.
+func (e *OptOutCancelledEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *OptOutCancelledEvent) Validate() error {
+	return nil
+}
+
+// NewOptOutCancelledEvent creates new instance of OptOutCancelledEvent.
+func NewOptOutCancelledEvent() *OptOutCancelledEvent {
+	return &OptOutCancelledEvent{}
+}
+
+// OptOutEffectiveEvent is the "Opt Out Effective" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.8.4
+type OptOutEffectiveEvent struct{}
+
+// EventType implements Event.
+func (e *OptOutEffectiveEvent) EventType() string {
+	return EventTypeOptOutEffective
+}
+
+// SubjectFormats implements Event.
+func (e *OptOutEffectiveEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *OptOutEffectiveEvent) Validate() error {
+	return nil
+}
+
+// NewOptOutEffectiveEvent creates new instance of OptOutEffectiveEvent.
+func NewOptOutEffectiveEvent() *OptOutEffectiveEvent {
+	return &OptOutEffectiveEvent{}
+}
+
+// RecoveryActivatedEvent is the "Recovery Activated" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.9
+type RecoveryActivatedEvent struct{}
+
+// EventType implements Event.
+func (e *RecoveryActivatedEvent) EventType() string {
+	return EventTypeRecoveryActivated
+}
+
+// SubjectFormats implements Event.
+func (e *RecoveryActivatedEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *RecoveryActivatedEvent) Validate() error {
+	return nil
+}
+
+// NewRecoveryActivatedEvent creates new instance of RecoveryActivatedEvent.
+func NewRecoveryActivatedEvent() *RecoveryActivatedEvent {
+	return &RecoveryActivatedEvent{}
+}
+
+// RecoveryInformationChangedEvent is the "Recovery Information Changed" event of RISC.
+// Reference: https://openid.net/specs/openid-risc-profile-specification-1_0.html#rfc.section.2.10
+type RecoveryInformationChangedEvent struct{}
+
+// EventType implements Event.
+func (e *RecoveryInformationChangedEvent) EventType() string {
+	return EventTypeRecoveryInformationChanged
+}
+
+// SubjectFormats implements Event.
+func (e *RecoveryInformationChangedEvent) SubjectFormats() []Format {
+	return riscAccountSubjectFormats
+}
+
+// Validate implements Event.
+func (e *RecoveryInformationChangedEvent) Validate() error {
+	return nil
+}
+
+// NewRecoveryInformationChangedEvent creates new instance of RecoveryInformationChangedEvent.
+func NewRecoveryInformationChangedEvent() *RecoveryInformationChangedEvent {
+	return &RecoveryInformationChangedEvent{}
+}
@@ -0,0 +1,187 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestRISCEvents(t *testing.T) {
+	disabled, _ := secevsubid.NewAccountDisabledEvent(secevsubid.AccountDisabledReasonHijacking)
+	compromise, _ := secevsubid.NewCredentialCompromiseEvent("password")
+	newEmail, _ := secevsubid.NewEmailIdentifier("new@example.com")
+	changed, _ := secevsubid.NewIdentifierChangedEvent(newEmail)
+
+	tests := []struct {
+		name  string
+		event secevsubid.Event
+		json  string
+	}{
+		{
+			name:  "account credential change required",
+			event: secevsubid.NewAccountCredentialChangeRequiredEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "account purged",
+			event: secevsubid.NewAccountPurgedEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "account disabled",
+			event: disabled,
+			json:  `{"reason":"hijacking"}`,
+		},
+		{
+			name:  "account enabled",
+			event: secevsubid.NewAccountEnabledEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "identifier changed",
+			event: changed,
+			json:  `{"new-value":"new@example.com"}`,
+		},
+		{
+			name:  "identifier recycled",
+			event: secevsubid.NewIdentifierRecycledEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "credential compromise",
+			event: compromise,
+			json:  `{"credential_type":"password"}`,
+		},
+		{
+			name:  "opt in",
+			event: secevsubid.NewOptInEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "opt out initiated",
+			event: secevsubid.NewOptOutInitiatedEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "opt out cancelled",
+			event: secevsubid.NewOptOutCancelledEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "opt out effective",
+			event: secevsubid.NewOptOutEffectiveEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "recovery activated",
+			event: secevsubid.NewRecoveryActivatedEvent(),
+			json:  `{}`,
+		},
+		{
+			name:  "recovery information changed",
+			event: secevsubid.NewRecoveryInformationChangedEvent(),
+			json:  `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newTestSET(t, "jti")
+			set.Events = nil
+			if err := set.AddEvent(tt.event); err != nil {
+				t.Error(err)
+				return
+			}
+			if got := string(set.Events[tt.event.EventType()]); got != tt.json {
+				t.Errorf("AddEvent() got = %s, want %s", got, tt.json)
+			}
+
+			es, err := set.ValidateEvents()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(es, []secevsubid.Event{tt.event}) {
+				t.Errorf("ValidateEvents() got = %v, want %v", es[0], tt.event)
+			}
+		})
+	}
+}
+
+func TestNewAccountDisabledEventWithUnknownReason(t *testing.T) {
+	if _, err := secevsubid.NewAccountDisabledEvent("unknown"); !errors.Is(err, secevsubid.ErrInvalidEventMember) {
+		t.Errorf("NewAccountDisabledEvent() error = %v, wantErr %v", err, secevsubid.ErrInvalidEventMember)
+	}
+}
+
+func TestNewCredentialCompromiseEventWithEmptyCredentialType(t *testing.T) {
+	if _, err := secevsubid.NewCredentialCompromiseEvent(""); !errors.Is(err, secevsubid.ErrMissingEventMember) {
+		t.Errorf("NewCredentialCompromiseEvent() error = %v, wantErr %v", err, secevsubid.ErrMissingEventMember)
+	}
+}
+
+func TestIdentifierChangedEvent(t *testing.T) {
+	oldPhone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	newPhone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550199")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("11112222333344445555")
+
+	e, err := secevsubid.NewIdentifierChangedEvent(newPhone)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := e.NewSubject(oldPhone)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got, newPhone) {
+		t.Errorf("NewSubject() got = %v, want %v", got, newPhone)
+	}
+
+	if err = secevsubid.ValidateEventSubject(e, opaque); !errors.Is(err, secevsubid.ErrSubjectFormatNotAllowed) {
+		t.Errorf("ValidateEventSubject() error = %v, wantErr %v", err, secevsubid.ErrSubjectFormatNotAllowed)
+	}
+	if _, err = secevsubid.NewIdentifierChangedEvent(opaque); err == nil {
+		t.Error("error should be raised when new value is neither email nor phone number")
+	}
+}
+
+func TestIdentifierChangedEvent_NewSubject(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	newEmail, _ := secevsubid.NewEmailIdentifier("new@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	newPhone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550199")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	emailAndIssSub, _ := secevsubid.NewAliasesIdentifier(issSub, email)
+	emailAndPhone, _ := secevsubid.NewAliasesIdentifier(email, phone)
+	issSubOnly, _ := secevsubid.NewAliasesIdentifier(issSub)
+
+	tests := []struct {
+		name     string
+		newValue string
+		subject  secevsubid.SubjectIdentifier
+		want     secevsubid.SubjectIdentifier
+		wantErr  error
+	}{
+		{name: "nil subject", newValue: "new@example.com", subject: nil, wantErr: secevsubid.ErrNoSubject},
+		{name: "aliases with email", newValue: "new@example.com", subject: emailAndIssSub, want: newEmail},
+		{name: "aliases with email and phone for email", newValue: "new@example.com", subject: emailAndPhone, want: newEmail},
+		{name: "aliases with email and phone for phone", newValue: "+12065550199", subject: emailAndPhone, want: newPhone},
+		{name: "aliases without email and phone", newValue: "new@example.com", subject: issSubOnly, wantErr: secevsubid.ErrSubjectFormatNotAllowed},
+		{name: "empty new value", newValue: "", subject: email, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &secevsubid.IdentifierChangedEvent{NewValue: tt.newValue}
+			got, err := e.NewSubject(tt.subject)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSubject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSubject() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
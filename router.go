@@ -0,0 +1,340 @@
+package secevsubid
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// EventContext holds a single event dispatched by Router with the SET it came from.
+type EventContext struct {
+	// SET is the Security Event Token holding the event.
+	SET *SecurityEventToken
+	// Event is the decoded event.
+	Event Event
+	// Subject is the subject of the event.
+	Subject SubjectIdentifier
+}
+
+// EventHandler handles events dispatched by Router.
+type EventHandler interface {
+	// HandleEvent handles the event. The returned error is reported by Router.Dispatch.
+	HandleEvent(ctx context.Context, ec *EventContext) error
+}
+
+// EventHandlerFunc is an adapter to allow the use of ordinary functions as EventHandler.
+type EventHandlerFunc func(ctx context.Context, ec *EventContext) error
+
+// HandleEvent implements EventHandler.
+func (f EventHandlerFunc) HandleEvent(ctx context.Context, ec *EventContext) error {
+	return f(ctx, ec)
+}
+
+// Middleware wraps EventHandler to add behavior such as logging or recovery.
+type Middleware func(next EventHandler) EventHandler
+
+// SubjectMatcher is a predicate on the subject of the event.
+type SubjectMatcher func(subject SubjectIdentifier) bool
+
+// Route is the condition for Router to dispatch events to the handler.
+// Zero value fields match any events.
+type Route struct {
+	// EventType is the event type URI.
+	EventType string
+	// Format is the format of the subject.
+	// For AliasesIdentifier and ComplexIdentifier, it matches when any member has the format.
+	Format Format
+	// Match is the predicate on the subject.
+	Match SubjectMatcher
+}
+
+func (r *Route) matches(ec *EventContext) bool {
+	if r.EventType != "" && r.EventType != ec.Event.EventType() {
+		return false
+	}
+
+	if r.Format != "" && (ec.Subject == nil || !hasFormat(ec.Subject, r.Format)) {
+		return false
+	}
+
+	return r.Match == nil || (ec.Subject != nil && r.Match(ec.Subject))
+}
+
+func hasFormat(id SubjectIdentifier, f Format) bool {
+	return anySubject(id, func(id SubjectIdentifier) bool {
+		return id.Format() == f
+	})
+}
+
+func subjectMembers(id SubjectIdentifier) []SubjectIdentifier {
+	switch v := id.(type) {
+	case AliasesIdentifier:
+		return v.Identifiers()
+	case ComplexIdentifier:
+		var ids []SubjectIdentifier
+		for _, m := range v.Members() {
+			ids = append(ids, m)
+			ids = append(ids, subjectMembers(m)...)
+		}
+		return ids
+	}
+
+	return nil
+}
+
+func anySubject(id SubjectIdentifier, f func(id SubjectIdentifier) bool) bool {
+	if f(id) {
+		return true
+	}
+
+	for _, m := range subjectMembers(id) {
+		if f(m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchEmailDomain returns SubjectMatcher matching email identifiers whose domain is the argument, case-insensitively.
+func MatchEmailDomain(domain string) SubjectMatcher {
+	suffix := "@" + strings.ToLower(domain)
+	return func(subject SubjectIdentifier) bool {
+		return anySubject(subject, func(id SubjectIdentifier) bool {
+			e, ok := id.(EmailIdentifier)
+			return ok && strings.HasSuffix(strings.ToLower(e.Email()), suffix)
+		})
+	}
+}
+
+// MatchIssuer returns SubjectMatcher matching identifiers whose issuer is the argument,
+// i.e. Issuer and Subject, JWT ID and SAML Assertion ID Identifier Format.
+func MatchIssuer(issuer string) SubjectMatcher {
+	type issuerIdentifier interface {
+		Issuer() string
+	}
+	return func(subject SubjectIdentifier) bool {
+		return anySubject(subject, func(id SubjectIdentifier) bool {
+			i, ok := id.(issuerIdentifier)
+			return ok && i.Issuer() == issuer
+		})
+	}
+}
+
+// MatchSubjectIdentifier returns SubjectMatcher matching subjects which match the pattern by MatchSubject.
+func MatchSubjectIdentifier(pattern SubjectIdentifier) SubjectMatcher {
+	return func(subject SubjectIdentifier) bool {
+		return MatchSubject(pattern, subject)
+	}
+}
+
+type routeEntry struct {
+	route   Route
+	handler EventHandler
+}
+
+// Router dispatches events in Security Event Tokens to handlers registered by event type, format and subject.
+// An event is dispatched to all handlers whose route matches.
+type Router struct {
+	// Workers is the number of goroutines handling events concurrently in Dispatch and Serve.
+	// If zero or less, events are handled one by one.
+	Workers int
+	// NotFound handles events matching no routes. If nil, such events are ignored.
+	NotFound EventHandler
+	// JTIStore detects redelivered SETs in Dispatch and Serve using HandleOnce.
+	// Handlers are not called for redelivered SETs, and ErrReplayedSET is returned instead.
+	// If nil, replays are not detected.
+	JTIStore JTIStore
+
+	mu          sync.RWMutex
+	routes      []routeEntry
+	middlewares []Middleware
+}
+
+// NewRouter creates new instance of Router.
+func NewRouter(workers int) *Router {
+	return &Router{Workers: workers}
+}
+
+// Use adds middlewares applied to all handlers. Middlewares added first are outermost.
+func (r *Router) Use(mws ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, mws...)
+}
+
+// Handle registers the handler for the route.
+func (r *Router) Handle(route Route, h EventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, routeEntry{route: route, handler: h})
+}
+
+// HandleFunc registers the function for the route.
+func (r *Router) HandleFunc(route Route, f func(ctx context.Context, ec *EventContext) error) {
+	r.Handle(route, EventHandlerFunc(f))
+}
+
+func (r *Router) handlersFor(ec *EventContext) []EventHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hs []EventHandler
+	for _, e := range r.routes {
+		if e.route.matches(ec) {
+			hs = append(hs, r.wrap(e.handler))
+		}
+	}
+	if len(hs) == 0 && r.NotFound != nil {
+		hs = append(hs, r.wrap(r.NotFound))
+	}
+
+	return hs
+}
+
+func (r *Router) wrap(h EventHandler) EventHandler {
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+
+	return h
+}
+
+type routedEvent struct {
+	ec      *EventContext
+	handler EventHandler
+}
+
+// Dispatch decodes events in the SET and calls matching handlers using the worker pool.
+// It waits until all handlers finish and returns the errors joined by DispatchError if any.
+func (r *Router) Dispatch(ctx context.Context, set *SecurityEventToken) error {
+	return r.dispatch(ctx, set, r.Workers)
+}
+
+func (r *Router) dispatch(ctx context.Context, set *SecurityEventToken, workers int) error {
+	jobs, err := r.jobs(set)
+	if err != nil {
+		return err
+	}
+	if r.JTIStore == nil {
+		return runHandlers(ctx, jobs, workers)
+	}
+
+	return HandleOnce(ctx, r.JTIStore, set, func(ctx context.Context, set *SecurityEventToken) error {
+		return runHandlers(ctx, jobs, workers)
+	})
+}
+
+func (r *Router) jobs(set *SecurityEventToken) ([]routedEvent, error) {
+	es, err := set.DecodeEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []routedEvent
+	for _, e := range es {
+		ec := &EventContext{SET: set, Event: e, Subject: set.Subject()}
+		for _, h := range r.handlersFor(ec) {
+			jobs = append(jobs, routedEvent{ec: ec, handler: h})
+		}
+	}
+
+	return jobs, nil
+}
+
+func runHandlers(ctx context.Context, jobs []routedEvent, workers int) error {
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	ch := make(chan routedEvent)
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				if err := j.handler.HandleEvent(ctx, j.ec); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, j := range jobs {
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &DispatchError{Errors: errs}
+	}
+	return nil
+}
+
+// Serve dispatches SETs received from the channel until the channel is closed or ctx is done.
+// Up to Workers SETs are dispatched concurrently, and handlers for each SET are called one by one. Errors are passed to onError if it's not nil.
+func (r *Router) Serve(ctx context.Context, sets <-chan *SecurityEventToken, onError func(set *SecurityEventToken, err error)) error {
+	workers := r.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case set, ok := <-sets:
+			if !ok {
+				return nil
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				if err := r.dispatch(ctx, set, 1); err != nil && onError != nil {
+					onError(set, err)
+				}
+			}()
+		}
+	}
+}
+
+// DispatchError holds errors returned by handlers.
+type DispatchError struct {
+	// Errors is the list of errors returned by handlers.
+	Errors []error
+}
+
+// Error implements error.
+func (e *DispatchError) Error() string {
+	ss := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		ss[i] = err.Error()
+	}
+	return "dispatch failed: " + strings.Join(ss, "; ")
+}
+
+// Unwrap returns the errors returned by handlers, so errors.Is and errors.As examine each of them.
+func (e *DispatchError) Unwrap() []error {
+	return e.Errors
+}
@@ -0,0 +1,224 @@
+package secevsubid_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/pinzolo/secevsubid"
+	"io/fs"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRouterTestSET(t *testing.T, subject secevsubid.SubjectIdentifier, eventTypes ...string) *secevsubid.SecurityEventToken {
+	t.Helper()
+	set := newTestSET(t, "jti")
+	set.SubId = secevsubid.NewWrapper(subject)
+	set.Events = make(map[string]json.RawMessage)
+	for _, et := range eventTypes {
+		set.Events[et] = json.RawMessage(`{}`)
+	}
+	return set
+}
+
+func TestRouter_Dispatch(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@Example.com")
+	otherEmail, _ := secevsubid.NewEmailIdentifier("user@other.example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	aliases, _ := secevsubid.NewAliasesIdentifier(otherEmail, issSub)
+
+	var mu sync.Mutex
+	var got []string
+	record := func(name string) secevsubid.EventHandlerFunc {
+		return func(ctx context.Context, ec *secevsubid.EventContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, name)
+			return nil
+		}
+	}
+
+	r := secevsubid.NewRouter(4)
+	r.Handle(secevsubid.Route{EventType: secevsubid.EventTypeAccountDisabled}, record("disabled"))
+	r.Handle(secevsubid.Route{Format: secevsubid.FormatIssuerSubject}, record("iss_sub"))
+	r.Handle(secevsubid.Route{Match: secevsubid.MatchEmailDomain("example.com")}, record("example.com"))
+	r.Handle(secevsubid.Route{EventType: secevsubid.EventTypeAccountPurged, Match: secevsubid.MatchIssuer("https://issuer.example.com/")}, record("purged by issuer"))
+	r.NotFound = record("not found")
+
+	tests := []struct {
+		name string
+		set  *secevsubid.SecurityEventToken
+		want []string
+	}{
+		{
+			name: "event type and email domain",
+			set:  newRouterTestSET(t, email, secevsubid.EventTypeAccountDisabled),
+			want: []string{"disabled", "example.com"},
+		},
+		{
+			name: "aliases member format and issuer",
+			set:  newRouterTestSET(t, aliases, secevsubid.EventTypeAccountPurged),
+			want: []string{"iss_sub", "purged by issuer"},
+		},
+		{
+			name: "not found",
+			set:  newRouterTestSET(t, otherEmail, secevsubid.EventTypeAccountEnabled),
+			want: []string{"not found"},
+		},
+		{
+			name: "multiple events",
+			set:  newRouterTestSET(t, issSub, secevsubid.EventTypeAccountDisabled, secevsubid.EventTypeAccountPurged),
+			want: []string{"disabled", "iss_sub", "iss_sub", "purged by issuer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			if err := r.Dispatch(context.Background(), tt.set); err != nil {
+				t.Error(err)
+				return
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Errorf("Dispatch() handled = %v, want %v", got, tt.want)
+				return
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Dispatch() handled = %v, want %v", got, tt.want)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_Use(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	var order []string
+	mw := func(name string) secevsubid.Middleware {
+		return func(next secevsubid.EventHandler) secevsubid.EventHandler {
+			return secevsubid.EventHandlerFunc(func(ctx context.Context, ec *secevsubid.EventContext) error {
+				order = append(order, name)
+				return next.HandleEvent(ctx, ec)
+			})
+		}
+	}
+
+	r := secevsubid.NewRouter(1)
+	r.Use(mw("outer"), mw("inner"))
+	r.HandleFunc(secevsubid.Route{}, func(ctx context.Context, ec *secevsubid.EventContext) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	if err := r.Dispatch(context.Background(), newRouterTestSET(t, email, secevsubid.EventTypeAccountEnabled)); err != nil {
+		t.Error(err)
+		return
+	}
+	want := []string{"outer", "inner", "handler"}
+	if len(order) != 3 || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] {
+		t.Errorf("call order = %v, want %v", order, want)
+	}
+}
+
+func TestRouter_DispatchWithError(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	errUnknownUser := errors.New("unknown user")
+	r := secevsubid.NewRouter(2)
+	r.HandleFunc(secevsubid.Route{}, func(ctx context.Context, ec *secevsubid.EventContext) error {
+		return errUnknownUser
+	})
+
+	err := r.Dispatch(context.Background(), newRouterTestSET(t, email, secevsubid.EventTypeAccountEnabled))
+	if !errors.Is(err, errUnknownUser) {
+		t.Errorf("Dispatch() error = %v, wantErr %v", err, errUnknownUser)
+	}
+}
+
+func TestRouter_DispatchWithJTIStore(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	set := newRouterTestSET(t, email, secevsubid.EventTypeAccountEnabled)
+	var handled int32
+	r := secevsubid.NewRouter(1)
+	r.JTIStore = secevsubid.NewMemoryJTIStore(time.Hour)
+	r.HandleFunc(secevsubid.Route{}, func(ctx context.Context, ec *secevsubid.EventContext) error {
+		if atomic.AddInt32(&handled, 1) == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := r.Dispatch(ctx, set); err == nil {
+		t.Error("Dispatch() error = nil, want handler error")
+	}
+	if err := r.Dispatch(ctx, set); err != nil {
+		t.Errorf("Dispatch() error = %v, want nil after failure", err)
+	}
+	if err := r.Dispatch(ctx, set); !errors.Is(err, secevsubid.ErrReplayedSET) {
+		t.Errorf("Dispatch() error = %v, wantErr %v", err, secevsubid.ErrReplayedSET)
+	}
+	if handled != 2 {
+		t.Errorf("handled = %d, want 2", handled)
+	}
+}
+
+func TestDispatchError_Unwrap(t *testing.T) {
+	errUnknownUser := errors.New("unknown user")
+	pathErr := &fs.PathError{Op: "open", Path: "users.json", Err: fs.ErrNotExist}
+	var err error = &secevsubid.DispatchError{Errors: []error{errUnknownUser, fmt.Errorf("handle: %w", context.Canceled), pathErr}}
+
+	if !errors.Is(err, errUnknownUser) {
+		t.Errorf("errors.Is(err, %v) = false, want true", errUnknownUser)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("errors.Is(err, %v) = false, want true", context.Canceled)
+	}
+	var got *fs.PathError
+	if !errors.As(err, &got) || got != pathErr {
+		t.Errorf("errors.As() got = %v, want %v", got, pathErr)
+	}
+}
+
+func TestRouter_Serve(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	var running, maxRunning, handled int32
+	r := secevsubid.NewRouter(2)
+	r.HandleFunc(secevsubid.Route{}, func(ctx context.Context, ec *secevsubid.EventContext) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&handled, 1)
+		return nil
+	})
+
+	sets := make(chan *secevsubid.SecurityEventToken)
+	go func() {
+		for i := 0; i < 10; i++ {
+			sets <- newRouterTestSET(t, email, secevsubid.EventTypeAccountEnabled)
+		}
+		close(sets)
+	}()
+
+	if err := r.Serve(context.Background(), sets, nil); err != nil {
+		t.Error(err)
+		return
+	}
+	if handled != 10 {
+		t.Errorf("handled = %d, want 10", handled)
+	}
+	if maxRunning > 2 {
+		t.Errorf("max concurrent handlers = %d, want at most 2", maxRunning)
+	}
+}
@@ -0,0 +1,92 @@
+package secevsubid
+
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
+// SamlAssertionIdIdentifier is one of the sub-interfaces of SubjectIdentifier.
+// It represents the "SAML Assertion ID Subject Identifier Format" defined in the OpenID Shared Signals Framework specification.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-saml-assertion-id-subject-i
+type SamlAssertionIdIdentifier interface {
+	// Format returns name of the format actually held by the instance.
+	// The value is the fixed value "saml_assertion_id".
+	Format() Format
+	// Issuer returns issuer value held by the instance.
+	Issuer() string
+	// AssertionId returns assertion_id value held by the instance.
+	AssertionId() string
+	// Validate values held and returns an error if there is a problem.
+	Validate() error
+}
+
+type samlAssertionIdIdentifier struct {
+	F Format `json:"format"`
+	I string `json:"issuer"`
+	A string `json:"assertion_id"`
+}
+
+func (id *samlAssertionIdIdentifier) Format() Format {
+	return id.F
+}
+
+func (id *samlAssertionIdIdentifier) Issuer() string {
+	return id.I
+}
+
+func (id *samlAssertionIdIdentifier) AssertionId() string {
+	return id.A
+}
+
+func (id *samlAssertionIdIdentifier) Validate() error {
+	if id.I == "" {
+		return ErrEmptyIssuer
+	}
+
+	if id.A == "" {
+		return ErrEmptyAssertionId
+	}
+
+	return nil
+}
+
+func (id *samlAssertionIdIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *samlAssertionIdIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *samlAssertionIdIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *samlAssertionIdIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "samlAssertionIdIdentifier")
+}
+
+func (id *samlAssertionIdIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatSamlAssertionId)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*samlAssertionIdIdentifier)
+	return nil
+}
+
+// NewSamlAssertionIdIdentifier creates new instance of SamlAssertionIdIdentifier.
+// The argument "issuer" and "assertionId" is required. If either one of them is empty, this function returns error.
+func NewSamlAssertionIdIdentifier(issuer string, assertionId string) (SamlAssertionIdIdentifier, error) {
+	id := &samlAssertionIdIdentifier{
+		F: FormatSamlAssertionId,
+		I: issuer,
+		A: assertionId,
+	}
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
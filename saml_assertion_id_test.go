@@ -0,0 +1,52 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pinzolo/secevsubid"
+	"testing"
+)
+
+func TestSamlAssertionIdIdentifier(t *testing.T) {
+	wantIssuer := "https://idp.example.com/123456789/"
+	wantAssertionId := "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6"
+	id, err := secevsubid.NewSamlAssertionIdIdentifier(wantIssuer, wantAssertionId)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if id.Format() != secevsubid.FormatSamlAssertionId {
+		t.Errorf("invalid format: got = %s, want = %s", id.Format(), secevsubid.FormatSamlAssertionId)
+	}
+	if id.Issuer() != wantIssuer {
+		t.Errorf("invalid issuer: got = %s, want = %s", id.Issuer(), wantIssuer)
+	}
+	if id.AssertionId() != wantAssertionId {
+		t.Errorf("invalid assertion_id: got = %s, want = %s", id.AssertionId(), wantAssertionId)
+	}
+
+	wantJSON := fmt.Sprintf(`{"format":"saml_assertion_id","issuer":"%s","assertion_id":"%s"}`, wantIssuer, wantAssertionId)
+	b, err := json.Marshal(id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(b) != wantJSON {
+		t.Errorf("invalid JSON conversion: got = %s, want = %s", string(b), wantJSON)
+	}
+}
+
+func TestSamlAssertionIdIdentifierWithEmptyIssuer(t *testing.T) {
+	_, err := secevsubid.NewSamlAssertionIdIdentifier("", "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6")
+	if err == nil {
+		t.Error("error should be raised when issuer is empty")
+	}
+}
+
+func TestSamlAssertionIdIdentifierWithEmptyAssertionId(t *testing.T) {
+	_, err := secevsubid.NewSamlAssertionIdIdentifier("https://idp.example.com/123456789/", "")
+	if err == nil {
+		t.Error("error should be raised when assertion_id is empty")
+	}
+}
@@ -0,0 +1,95 @@
+package secevsubid
+
+import (
+	"fmt"
+)
+
+// SAML NameID formats defined in SAML 2.0 Core section 8.3.
+// Reference: https://docs.oasis-open.org/security/saml/v2.0/saml-core-2.0-os.pdf
+const (
+	NameIDFormatUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	NameIDFormatEmailAddress = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIDFormatEntity       = "urn:oasis:names:tc:SAML:2.0:nameid-format:entity"
+	NameIDFormatPersistent   = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	NameIDFormatTransient    = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+)
+
+// NameID represents SAML NameID element.
+type NameID struct {
+	// Value is the identifier.
+	Value string `xml:",chardata"`
+	// Format is the URI of the NameID format. Empty means unspecified.
+	Format string `xml:"Format,attr,omitempty"`
+	// NameQualifier is the security or administrative domain qualifying the identifier, usually the IdP entity ID.
+	NameQualifier string `xml:"NameQualifier,attr,omitempty"`
+	// SPNameQualifier is the service provider or affiliation the identifier is scoped to.
+	SPNameQualifier string `xml:"SPNameQualifier,attr,omitempty"`
+}
+
+// SubjectFromNameID converts SAML NameID to the best-fit SubjectIdentifier.
+//   - emailAddress is converted to Email Identifier.
+//   - persistent and unspecified are converted to Issuer and Subject Identifier with NameQualifier as "iss",
+//     or Opaque Identifier if NameQualifier is empty.
+//   - entity is converted to URI Identifier.
+//   - transient results in ErrTransientNameID, and other formats result in ErrUnsupportedNameIDFormat.
+//
+// SPNameQualifier is not held by converted identifiers.
+func SubjectFromNameID(n NameID) (SubjectIdentifier, error) {
+	switch n.Format {
+	case NameIDFormatEmailAddress:
+		return NewEmailIdentifier(n.Value)
+	case NameIDFormatPersistent, NameIDFormatUnspecified, "":
+		if n.NameQualifier != "" {
+			return NewIssuerSubjectIdentifier(n.NameQualifier, n.Value)
+		}
+		return NewOpaqueIdentifier(n.Value)
+	case NameIDFormatEntity:
+		return NewUriIdentifier(n.Value)
+	case NameIDFormatTransient:
+		return nil, ErrTransientNameID
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedNameIDFormat, n.Format)
+}
+
+// NameIDFromSubject converts SubjectIdentifier to SAML NameID, which is reverse of SubjectFromNameID.
+// AliasesIdentifier is converted via its first convertible member, and ComplexIdentifier via its "user" member.
+func NameIDFromSubject(id SubjectIdentifier) (NameID, error) {
+	if id == nil {
+		return NameID{}, ErrNoSubject
+	}
+
+	switch id.Format() {
+	case FormatEmail:
+		if v, ok := id.(EmailIdentifier); ok {
+			return NameID{Value: v.Email(), Format: NameIDFormatEmailAddress}, nil
+		}
+	case FormatIssuerSubject:
+		if v, ok := id.(IssuerSubjectIdentifier); ok {
+			return NameID{Value: v.Subject(), Format: NameIDFormatPersistent, NameQualifier: v.Issuer()}, nil
+		}
+	case FormatOpaque:
+		if v, ok := id.(OpaqueIdentifier); ok {
+			return NameID{Value: v.Id(), Format: NameIDFormatPersistent}, nil
+		}
+	case FormatUri:
+		if v, ok := id.(UriIdentifier); ok {
+			return NameID{Value: v.Uri(), Format: NameIDFormatEntity}, nil
+		}
+	case FormatAliases:
+		if v, ok := id.(AliasesIdentifier); ok {
+			for _, m := range v.Identifiers() {
+				if n, err := NameIDFromSubject(m); err == nil {
+					return n, nil
+				}
+			}
+			return NameID{}, fmt.Errorf("%w: no member of aliases is convertible to name id", ErrNoAcceptableSubject)
+		}
+	case FormatComplex:
+		if v, ok := id.(ComplexIdentifier); ok && v.User() != nil {
+			return NameIDFromSubject(v.User())
+		}
+	}
+
+	return NameID{}, fmt.Errorf("%w: %s is not convertible to name id", ErrNoAcceptableSubject, id.Format())
+}
@@ -0,0 +1,103 @@
+package secevsubid_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestSubjectFromNameID(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://idp.example.com/", "a1b2c3")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("a1b2c3")
+	uri, _ := secevsubid.NewUriIdentifier("https://sp.example.com/")
+
+	tests := []struct {
+		name    string
+		nameID  secevsubid.NameID
+		want    secevsubid.SubjectIdentifier
+		wantErr error
+	}{
+		{name: "email", nameID: secevsubid.NameID{Value: "user@example.com", Format: secevsubid.NameIDFormatEmailAddress}, want: email},
+		{name: "persistent with qualifier", nameID: secevsubid.NameID{Value: "a1b2c3", Format: secevsubid.NameIDFormatPersistent, NameQualifier: "https://idp.example.com/", SPNameQualifier: "https://sp.example.com/"}, want: issSub},
+		{name: "persistent without qualifier", nameID: secevsubid.NameID{Value: "a1b2c3", Format: secevsubid.NameIDFormatPersistent}, want: opaque},
+		{name: "unspecified", nameID: secevsubid.NameID{Value: "a1b2c3"}, want: opaque},
+		{name: "entity", nameID: secevsubid.NameID{Value: "https://sp.example.com/", Format: secevsubid.NameIDFormatEntity}, want: uri},
+		{name: "transient", nameID: secevsubid.NameID{Value: "_abc", Format: secevsubid.NameIDFormatTransient}, wantErr: secevsubid.ErrTransientNameID},
+		{name: "x509", nameID: secevsubid.NameID{Value: "CN=user", Format: "urn:oasis:names:tc:SAML:1.1:nameid-format:X509SubjectName"}, wantErr: secevsubid.ErrUnsupportedNameIDFormat},
+		{name: "empty email", nameID: secevsubid.NameID{Format: secevsubid.NameIDFormatEmailAddress}, wantErr: secevsubid.ErrEmptyEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.SubjectFromNameID(tt.nameID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SubjectFromNameID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SubjectFromNameID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameIDFromSubject(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://idp.example.com/", "a1b2c3")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("a1b2c3")
+	uri, _ := secevsubid.NewUriIdentifier("https://sp.example.com/")
+	aliases, _ := secevsubid.NewAliasesIdentifier(phone, issSub)
+	phoneOnly, _ := secevsubid.NewAliasesIdentifier(phone)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: email, Device: opaque})
+
+	tests := []struct {
+		name    string
+		id      secevsubid.SubjectIdentifier
+		want    secevsubid.NameID
+		wantErr error
+	}{
+		{name: "email", id: email, want: secevsubid.NameID{Value: "user@example.com", Format: secevsubid.NameIDFormatEmailAddress}},
+		{name: "iss_sub", id: issSub, want: secevsubid.NameID{Value: "a1b2c3", Format: secevsubid.NameIDFormatPersistent, NameQualifier: "https://idp.example.com/"}},
+		{name: "opaque", id: opaque, want: secevsubid.NameID{Value: "a1b2c3", Format: secevsubid.NameIDFormatPersistent}},
+		{name: "uri", id: uri, want: secevsubid.NameID{Value: "https://sp.example.com/", Format: secevsubid.NameIDFormatEntity}},
+		{name: "aliases", id: aliases, want: secevsubid.NameID{Value: "a1b2c3", Format: secevsubid.NameIDFormatPersistent, NameQualifier: "https://idp.example.com/"}},
+		{name: "complex", id: complexSubject, want: secevsubid.NameID{Value: "user@example.com", Format: secevsubid.NameIDFormatEmailAddress}},
+		{name: "phone", id: phone, wantErr: secevsubid.ErrNoAcceptableSubject},
+		{name: "aliases without convertible member", id: phoneOnly, wantErr: secevsubid.ErrNoAcceptableSubject},
+		{name: "nil", id: nil, wantErr: secevsubid.ErrNoSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.NameIDFromSubject(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NameIDFromSubject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NameIDFromSubject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameID_XML(t *testing.T) {
+	src := `<NameID Format="urn:oasis:names:tc:SAML:2.0:nameid-format:persistent" NameQualifier="https://idp.example.com/">a1b2c3</NameID>`
+	var n secevsubid.NameID
+	if err := xml.Unmarshal([]byte(src), &n); err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := secevsubid.SubjectFromNameID(n)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want, _ := secevsubid.NewIssuerSubjectIdentifier("https://idp.example.com/", "a1b2c3")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SubjectFromNameID() = %v, want %v", got, want)
+	}
+}
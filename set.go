@@ -0,0 +1,235 @@
+package secevsubid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SETMediaType is the value of "typ" header of Security Event Token.
+	// Reference: https://datatracker.ietf.org/doc/html/rfc8417#section-2.3
+	SETMediaType = "secevent+jwt"
+	// AlgorithmHS256 is the name of HMAC using SHA-256 algorithm.
+	AlgorithmHS256 = "HS256"
+)
+
+// SecurityEventToken represents the claims of Security Event Token (SET) defined in RFC 8417.
+// The "sub_id" claim is held as SubjectIdentifier via Wrapper.
+// Reference: https://datatracker.ietf.org/doc/html/rfc8417
+type SecurityEventToken struct {
+	// Issuer is the value of "iss" claim.
+	Issuer string `json:"iss"`
+	// IssuedAt is the value of "iat" claim.
+	IssuedAt int64 `json:"iat"`
+	// JwtId is the value of "jti" claim.
+	JwtId string `json:"jti"`
+	// Audience is the value of "aud" claim.
+	Audience Audience `json:"aud,omitempty"`
+	// TransactionId is the value of "txn" claim.
+	TransactionId string `json:"txn,omitempty"`
+	// SubId is the value of "sub_id" claim.
+	SubId *Wrapper `json:"sub_id,omitempty"`
+	// Events is the value of "events" claim. Each key is event type URI.
+	Events map[string]json.RawMessage `json:"events"`
+}
+
+// Audience is the value of "aud" claim, which is a single string or an array of strings.
+// Reference: https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.3
+type Audience []string
+
+// Contains returns whether the audience has the value.
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON implements json.Marshaler.
+// A single value is encoded as string, and multiple values are encoded as array.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// Both string and array of strings are accepted.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return fmt.Errorf("aud must be string or array of strings: %w", err)
+	}
+	*a = ss
+	return nil
+}
+
+// Subject returns the SubjectIdentifier held in "sub_id" claim.
+// If "sub_id" claim does not exist, this method returns nil.
+func (set *SecurityEventToken) Subject() SubjectIdentifier {
+	if set.SubId == nil {
+		return nil
+	}
+	return set.SubId.Value()
+}
+
+// Validate values held and returns an error if there is a problem.
+func (set *SecurityEventToken) Validate() error {
+	if set.Issuer == "" {
+		return ErrEmptyIssuer
+	}
+
+	if set.JwtId == "" {
+		return ErrEmptyJwtId
+	}
+
+	if len(set.Events) == 0 {
+		return ErrEmptyEvents
+	}
+
+	if id := set.Subject(); id != nil {
+		return id.Validate()
+	}
+
+	return nil
+}
+
+// SETSigner signs Security Event Token at encoding time.
+type SETSigner interface {
+	// Algorithm returns the value of "alg" header.
+	Algorithm() string
+	// Sign returns the signature of the signing input.
+	Sign(signingInput []byte) ([]byte, error)
+}
+
+// SETVerifier verifies the signature of Security Event Token at decoding time.
+type SETVerifier interface {
+	// Verify returns an error if the signature is not valid for the algorithm and the signing input.
+	Verify(alg string, signingInput []byte, signature []byte) error
+}
+
+// HMACKey signs and verifies Security Event Token with HMAC using SHA-256 (HS256).
+type HMACKey []byte
+
+// Algorithm implements SETSigner.
+func (k HMACKey) Algorithm() string {
+	return AlgorithmHS256
+}
+
+// Sign implements SETSigner.
+func (k HMACKey) Sign(signingInput []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, k)
+	_, _ = mac.Write(signingInput)
+	return mac.Sum(nil), nil
+}
+
+// Verify implements SETVerifier.
+func (k HMACKey) Verify(alg string, signingInput []byte, signature []byte) error {
+	if alg != AlgorithmHS256 {
+		return fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+
+	want, _ := k.Sign(signingInput)
+	if !hmac.Equal(want, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+type setHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// EncodeSET validates the SecurityEventToken and encodes it to JWS compact serialization signed by the signer.
+func EncodeSET(set *SecurityEventToken, signer SETSigner) (string, error) {
+	if err := set.Validate(); err != nil {
+		return "", err
+	}
+
+	h, err := json.Marshal(setHeader{Alg: signer.Algorithm(), Typ: SETMediaType})
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(set)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(p)
+	sig, err := signer.Sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// isSETMediaType returns whether typ is SETMediaType.
+// As RFC 7515 section 4.1.9 allows, "application/" prefix may be omitted, and media types are compared case-insensitively.
+func isSETMediaType(typ string) bool {
+	const prefix = "application/"
+	if len(typ) > len(prefix) && strings.EqualFold(typ[:len(prefix)], prefix) {
+		typ = typ[len(prefix):]
+	}
+
+	return strings.EqualFold(typ, SETMediaType)
+}
+
+// DecodeSET verifies the signature of JWS compact serialized token by the verifier and decodes it to SecurityEventToken.
+// The "sub_id" claim is decoded via DecodeJSON, so an invalid subject identifier results in an error.
+func DecodeSET(token string, verifier SETVerifier) (*SecurityEventToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedSET
+	}
+
+	hb, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformedSET
+	}
+	var h setHeader
+	if err = json.Unmarshal(hb, &h); err != nil {
+		return nil, ErrMalformedSET
+	}
+	if h.Typ != "" && !isSETMediaType(h.Typ) {
+		return nil, fmt.Errorf("unexpected typ: %s", h.Typ)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedSET
+	}
+	if err = verifier.Verify(h.Alg, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	pb, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedSET
+	}
+	set := &SecurityEventToken{}
+	if err = json.Unmarshal(pb, set); err != nil {
+		return nil, err
+	}
+	if err = set.Validate(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
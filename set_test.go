@@ -0,0 +1,245 @@
+package secevsubid_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func b64(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func newTestSET(t *testing.T, jti string) *secevsubid.SecurityEventToken {
+	t.Helper()
+	email, err := secevsubid.NewEmailIdentifier("user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &secevsubid.SecurityEventToken{
+		Issuer:   "https://transmitter.example.com/",
+		IssuedAt: 1615305159,
+		JwtId:    jti,
+		Audience: secevsubid.Audience{"https://receiver.example.com/"},
+		SubId:    secevsubid.NewWrapper(email),
+		Events: map[string]json.RawMessage{
+			"https://schemas.openid.net/secevent/risc/event-type/account-disabled": json.RawMessage(`{}`),
+		},
+	}
+}
+
+func TestEncodeSET(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	set := newTestSET(t, "3d0c3cf797584bd193bd0fb1bd4e7d30")
+
+	token, err := secevsubid.EncodeSET(set, key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := secevsubid.DecodeSET(token, key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Subject().Format() != secevsubid.FormatEmail {
+		t.Errorf("invalid subject format: got = %s, want = %s", got.Subject().Format(), secevsubid.FormatEmail)
+	}
+	if !reflect.DeepEqual(got.Subject(), set.Subject()) {
+		t.Errorf("DecodeSET() subject = %v, want %v", got.Subject(), set.Subject())
+	}
+	if got.JwtId != set.JwtId || got.Issuer != set.Issuer || got.IssuedAt != set.IssuedAt {
+		t.Errorf("DecodeSET() got = %v, want %v", got, set)
+	}
+}
+
+func TestEncodeSETWithInvalidSET(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	tests := []struct {
+		name   string
+		modify func(set *secevsubid.SecurityEventToken)
+	}{
+		{
+			name:   "empty iss",
+			modify: func(set *secevsubid.SecurityEventToken) { set.Issuer = "" },
+		},
+		{
+			name:   "empty jti",
+			modify: func(set *secevsubid.SecurityEventToken) { set.JwtId = "" },
+		},
+		{
+			name:   "empty events",
+			modify: func(set *secevsubid.SecurityEventToken) { set.Events = nil },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newTestSET(t, "jti")
+			tt.modify(set)
+			if _, err := secevsubid.EncodeSET(set, key); err == nil {
+				t.Error("error should be raised when SET is invalid")
+			}
+		})
+	}
+}
+
+func TestDecodeSET(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	token, err := secevsubid.EncodeSET(newTestSET(t, "jti"), key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name     string
+		token    string
+		verifier secevsubid.SETVerifier
+		wantErr  error
+	}{
+		{
+			name:     "valid",
+			token:    token,
+			verifier: key,
+			wantErr:  nil,
+		},
+		{
+			name:     "other key",
+			token:    token,
+			verifier: secevsubid.HMACKey("other"),
+			wantErr:  secevsubid.ErrInvalidSignature,
+		},
+		{
+			name:     "not JWS",
+			token:    "abc.def",
+			verifier: key,
+			wantErr:  secevsubid.ErrMalformedSET,
+		},
+		{
+			name:     "tampered payload",
+			token:    parts[0] + "." + parts[1] + "e30." + parts[2],
+			verifier: key,
+			wantErr:  secevsubid.ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := secevsubid.DecodeSET(tt.token, tt.verifier)
+			if err != tt.wantErr {
+				t.Errorf("DecodeSET() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeSETWithInvalidSubject(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	header := `{"alg":"HS256","typ":"secevent+jwt"}`
+	payload := `{"iss":"https://transmitter.example.com/","iat":1615305159,"jti":"jti","sub_id":{"format":"email"},"events":{"https://example.com/event":{}}}`
+	input := b64(header) + "." + b64(payload)
+	sig, _ := key.Sign([]byte(input))
+
+	if _, err := secevsubid.DecodeSET(input+"."+b64(string(sig)), key); err == nil {
+		t.Error("error should be raised when sub_id is invalid")
+	}
+}
+
+func TestAudience_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    secevsubid.Audience
+		wantErr bool
+	}{
+		{name: "string", json: `"https://receiver.example.com/"`, want: secevsubid.Audience{"https://receiver.example.com/"}},
+		{name: "array", json: `["https://a.example.com/","https://b.example.com/"]`, want: secevsubid.Audience{"https://a.example.com/", "https://b.example.com/"}},
+		{name: "number", json: `1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got secevsubid.Audience
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, _ := json.Marshal(got)
+			if string(b) != tt.json {
+				t.Errorf("MarshalJSON() = %s, want %s", b, tt.json)
+			}
+		})
+	}
+}
+
+func TestDecodeSETWithAudienceArray(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	header := `{"alg":"HS256","typ":"secevent+jwt"}`
+	payload := `{"iss":"https://transmitter.example.com/","iat":1615305159,"jti":"jti","aud":["https://a.example.com/","https://b.example.com/"],"events":{"https://example.com/event":{}}}`
+	input := b64(header) + "." + b64(payload)
+	sig, _ := key.Sign([]byte(input))
+
+	set, err := secevsubid.DecodeSET(input+"."+b64(string(sig)), key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !set.Audience.Contains("https://b.example.com/") || set.Audience.Contains("https://c.example.com/") {
+		t.Errorf("Audience = %v, want a and b", set.Audience)
+	}
+}
+
+func TestDecodeSETWithMalformedSubject(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	header := `{"alg":"HS256","typ":"secevent+jwt"}`
+	subjects := []string{
+		`{"format":"aliases"}`,
+		`{"format":"aliases","identifiers":"x"}`,
+		`{"format":"email","email":["user@example.com"]}`,
+		`{"user":{"format":"opaque","id":1}}`,
+	}
+	for _, sub := range subjects {
+		payload := `{"iss":"https://transmitter.example.com/","iat":1615305159,"jti":"jti","sub_id":` + sub + `,"events":{"https://example.com/event":{}}}`
+		input := b64(header) + "." + b64(payload)
+		sig, _ := key.Sign([]byte(input))
+
+		if _, err := secevsubid.DecodeSET(input+"."+b64(string(sig)), key); err == nil {
+			t.Errorf("DecodeSET() error = nil for sub_id %s, want error", sub)
+		}
+	}
+}
+
+func TestDecodeSETWithMediaType(t *testing.T) {
+	key := secevsubid.HMACKey("secret")
+	payload := `{"iss":"https://transmitter.example.com/","iat":1615305159,"jti":"jti","events":{"https://example.com/event":{}}}`
+	tests := []struct {
+		typ     string
+		wantErr bool
+	}{
+		{typ: "secevent+jwt"},
+		{typ: "application/secevent+jwt"},
+		{typ: "Application/SECEVENT+JWT"},
+		{typ: "JWT", wantErr: true},
+		{typ: "application/jwt", wantErr: true},
+		{typ: "text/secevent+jwt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			input := b64(`{"alg":"HS256","typ":"`+tt.typ+`"}`) + "." + b64(payload)
+			sig, _ := key.Sign([]byte(input))
+			if _, err := secevsubid.DecodeSET(input+"."+b64(string(sig)), key); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeSET() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
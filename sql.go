@@ -0,0 +1,95 @@
+package secevsubid
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// NullSubjectIdentifier represents SubjectIdentifier which may be NULL in database columns.
+// It implements sql.Scanner and driver.Valuer with JSON representation of the identifier,
+// so that identifiers are stored in JSON or text columns.
+// Since Wrapper has Value method returning SubjectIdentifier, it cannot implement driver.Valuer.
+type NullSubjectIdentifier struct {
+	// Identifier is the identifier. It is nil when Valid is false.
+	Identifier SubjectIdentifier
+	// Valid is true if Identifier is not NULL.
+	Valid bool
+}
+
+// NewNullSubjectIdentifier creates new instance of NullSubjectIdentifier.
+// If the argument is nil, it represents NULL.
+func NewNullSubjectIdentifier(id SubjectIdentifier) NullSubjectIdentifier {
+	return NullSubjectIdentifier{Identifier: id, Valid: id != nil}
+}
+
+// Scan implements sql.Scanner.
+// The source must be NULL, or JSON representation of the identifier as string or []byte.
+// The identifier is decoded via DecodeJSON and validated.
+func (n *NullSubjectIdentifier) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		n.Identifier, n.Valid = nil, false
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into NullSubjectIdentifier", src)
+	}
+
+	id, err := DecodeJSON(b)
+	if err != nil {
+		return err
+	}
+	if err = id.Validate(); err != nil {
+		return err
+	}
+
+	n.Identifier, n.Valid = id, true
+	return nil
+}
+
+// Value implements driver.Valuer.
+// It returns JSON representation of the identifier as string, or nil if it's not Valid.
+func (n NullSubjectIdentifier) Value() (driver.Value, error) {
+	if !n.Valid || n.Identifier == nil {
+		return nil, nil
+	}
+	if err := n.Identifier.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(n.Identifier)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// Returns "null" if it's not Valid.
+func (n NullSubjectIdentifier) MarshalJSON() ([]byte, error) {
+	if !n.Valid || n.Identifier == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Identifier)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullSubjectIdentifier) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		n.Identifier, n.Valid = nil, false
+		return nil
+	}
+
+	id, err := DecodeJSON(b)
+	if err != nil {
+		return err
+	}
+
+	n.Identifier, n.Valid = id, true
+	return nil
+}
@@ -0,0 +1,95 @@
+package secevsubid_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+var (
+	_ sql.Scanner   = (*secevsubid.NullSubjectIdentifier)(nil)
+	_ driver.Valuer = secevsubid.NullSubjectIdentifier{}
+)
+
+func TestNullSubjectIdentifier_Scan(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    secevsubid.NullSubjectIdentifier
+		wantErr bool
+	}{
+		{name: "null", src: nil, want: secevsubid.NullSubjectIdentifier{}},
+		{name: "bytes", src: []byte(`{"format":"email","email":"user@example.com"}`), want: secevsubid.NewNullSubjectIdentifier(email)},
+		{name: "string", src: `{"format":"email","email":"user@example.com"}`, want: secevsubid.NewNullSubjectIdentifier(email)},
+		{name: "invalid identifier", src: `{"format":"email","email":""}`, wantErr: true},
+		{name: "unknown format", src: `{"format":"unknown"}`, wantErr: true},
+		{name: "unsupported type", src: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got secevsubid.NullSubjectIdentifier
+			err := got.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Scan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNullSubjectIdentifier_Value(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	tests := []struct {
+		name    string
+		n       secevsubid.NullSubjectIdentifier
+		want    driver.Value
+		wantErr bool
+	}{
+		{name: "null", n: secevsubid.NewNullSubjectIdentifier(nil), want: nil},
+		{name: "not valid", n: secevsubid.NullSubjectIdentifier{Identifier: email}, want: nil},
+		{name: "email", n: secevsubid.NewNullSubjectIdentifier(email), want: `{"format":"email","email":"user@example.com"}`},
+		{name: "invalid", n: secevsubid.NewNullSubjectIdentifier(&invalidIdentifier{}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.n.Value()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Value() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNullSubjectIdentifier_JSON(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	type record struct {
+		SubId secevsubid.NullSubjectIdentifier `json:"sub_id"`
+	}
+
+	for _, want := range []record{{SubId: secevsubid.NewNullSubjectIdentifier(email)}, {}} {
+		b, err := json.Marshal(want)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var got record
+		if err = json.Unmarshal(b, &got); err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", b, got, want)
+		}
+	}
+}
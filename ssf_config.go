@@ -0,0 +1,170 @@
+package secevsubid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DeliveryMethodPush is the delivery method URI of push-based SET delivery defined in RFC 8935.
+	DeliveryMethodPush = "urn:ietf:rfc:8935"
+	// DeliveryMethodPoll is the delivery method URI of poll-based SET delivery defined in RFC 8936.
+	DeliveryMethodPoll = "urn:ietf:rfc:8936"
+	// WellKnownSSFConfigurationPath is the well-known path of SSF transmitter configuration metadata.
+	WellKnownSSFConfigurationPath = "/.well-known/ssf-configuration"
+)
+
+// AuthorizationScheme is an element of "authorization_schemes" of TransmitterConfiguration.
+type AuthorizationScheme struct {
+	// SpecUrn is the URN describing the authorization specification, e.g. "urn:ietf:rfc:6749".
+	SpecUrn string `json:"spec_urn"`
+}
+
+// TransmitterConfiguration is the SSF transmitter configuration metadata served at /.well-known/ssf-configuration.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-transmitter-configuration-m
+type TransmitterConfiguration struct {
+	// SpecVersion is the version of SSF specification the transmitter supports.
+	SpecVersion string `json:"spec_version,omitempty"`
+	// Issuer is the URL the transmitter asserts as its issuer identifier.
+	Issuer string `json:"issuer"`
+	// JwksUri is the URL of the JSON Web Key Set of the transmitter.
+	JwksUri string `json:"jwks_uri,omitempty"`
+	// DeliveryMethodsSupported is the list of supported delivery method URIs.
+	DeliveryMethodsSupported []string `json:"delivery_methods_supported,omitempty"`
+	// ConfigurationEndpoint is the URL of the stream configuration endpoint.
+	ConfigurationEndpoint string `json:"configuration_endpoint,omitempty"`
+	// StatusEndpoint is the URL of the stream status endpoint.
+	StatusEndpoint string `json:"status_endpoint,omitempty"`
+	// AddSubjectEndpoint is the URL of the add subject endpoint.
+	AddSubjectEndpoint string `json:"add_subject_endpoint,omitempty"`
+	// RemoveSubjectEndpoint is the URL of the remove subject endpoint.
+	RemoveSubjectEndpoint string `json:"remove_subject_endpoint,omitempty"`
+	// VerificationEndpoint is the URL of the verification endpoint.
+	VerificationEndpoint string `json:"verification_endpoint,omitempty"`
+	// CriticalSubjectMembers is the list of Complex Subject member names the transmitter requires.
+	CriticalSubjectMembers []string `json:"critical_subject_members,omitempty"`
+	// AuthorizationSchemes is the list of supported authorization schemes.
+	AuthorizationSchemes []AuthorizationScheme `json:"authorization_schemes,omitempty"`
+	// DefaultSubjects is "ALL" or "NONE", which indicates subjects of new streams.
+	DefaultSubjects string `json:"default_subjects,omitempty"`
+	// SubjectFormatsSupported is the list of identifier formats the transmitter understands.
+	// This member is an extension to SSF specification. If it's empty, supported formats are unknown.
+	SubjectFormatsSupported []Format `json:"subject_formats_supported,omitempty"`
+}
+
+// Validate values held and returns an error if there is a problem.
+func (c *TransmitterConfiguration) Validate() error {
+	if c.Issuer == "" {
+		return ErrEmptyIssuer
+	}
+
+	if u, err := url.Parse(c.Issuer); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid issuer: %s", c.Issuer)
+	}
+
+	return nil
+}
+
+// SupportsDeliveryMethod returns whether the transmitter supports the delivery method.
+func (c *TransmitterConfiguration) SupportsDeliveryMethod(method string) bool {
+	for _, m := range c.DeliveryMethodsSupported {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportsFormat returns whether the transmitter understands the format in the same way as Capabilities.Supports.
+// If the transmitter does not declare supported formats, this method returns true.
+func (c *TransmitterConfiguration) SupportsFormat(f Format) bool {
+	return CapabilitiesFromConfiguration(c).Supports(f)
+}
+
+// NegotiateFormats returns formats supported by the transmitter in the order of the argument "preferred".
+// Formats are checked by Capabilities declared by the transmitter, see CapabilitiesFromConfiguration.
+func (c *TransmitterConfiguration) NegotiateFormats(preferred []Format) []Format {
+	caps := CapabilitiesFromConfiguration(c)
+	fs := make([]Format, 0, len(preferred))
+	for _, f := range preferred {
+		if caps.Supports(f) {
+			fs = append(fs, f)
+		}
+	}
+
+	return fs
+}
+
+// NewTransmitterConfigurationHandler creates new http.Handler serving the TransmitterConfiguration as JSON.
+// Mount it at WellKnownSSFConfigurationPath.
+func NewTransmitterConfigurationHandler(c *TransmitterConfiguration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSONError(w, http.StatusMethodNotAllowed, SETErrInvalidRequest, "method not allowed")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "max-age=3600")
+		writeJSON(w, http.StatusOK, c)
+	})
+}
+
+// ParseTransmitterConfiguration decodes the JSON to TransmitterConfiguration and validates it.
+func ParseTransmitterConfiguration(b []byte) (*TransmitterConfiguration, error) {
+	c := &TransmitterConfiguration{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// TransmitterConfigurationURL returns the URL of the TransmitterConfiguration of the issuer.
+// If the issuer has a path, the well-known path is inserted between the host and the path.
+func TransmitterConfigurationURL(issuer string) (string, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid issuer: %s", issuer)
+	}
+
+	u.Path = WellKnownSSFConfigurationPath + strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// FetchTransmitterConfiguration retrieves the TransmitterConfiguration of the issuer.
+// If the issuer in the response differs from the argument, this function returns error.
+// If the argument "client" is nil, http.DefaultClient is used.
+func FetchTransmitterConfiguration(ctx context.Context, client *http.Client, issuer string) (*TransmitterConfiguration, error) {
+	u, err := TransmitterConfigurationURL(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	if err = doJSON(ctx, client, http.MethodGet, u, nil, nil, &raw); err != nil {
+		return nil, err
+	}
+
+	c, err := ParseTransmitterConfiguration(raw)
+	if err != nil {
+		return nil, err
+	}
+	if c.Issuer != issuer {
+		return nil, fmt.Errorf("issuer mismatch: got = %s, want = %s", c.Issuer, issuer)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,145 @@
+package secevsubid_test
+
+import (
+	"context"
+	"github.com/pinzolo/secevsubid"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTransmitterConfigurationURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		issuer  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "without path",
+			issuer:  "https://tr.example.com",
+			want:    "https://tr.example.com/.well-known/ssf-configuration",
+			wantErr: false,
+		},
+		{
+			name:    "with path",
+			issuer:  "https://tr.example.com/issuer1",
+			want:    "https://tr.example.com/.well-known/ssf-configuration/issuer1",
+			wantErr: false,
+		},
+		{
+			name:    "not URL",
+			issuer:  "issuer1",
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.TransmitterConfigurationURL(tt.issuer)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TransmitterConfigurationURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("TransmitterConfigurationURL() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchTransmitterConfiguration(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &secevsubid.TransmitterConfiguration{
+		SpecVersion:              "1_0",
+		Issuer:                   srv.URL + "/issuer1",
+		DeliveryMethodsSupported: []string{secevsubid.DeliveryMethodPush, secevsubid.DeliveryMethodPoll},
+		ConfigurationEndpoint:    srv.URL + "/ssf/stream",
+		AddSubjectEndpoint:       srv.URL + "/ssf/subjects:add",
+		SubjectFormatsSupported:  []secevsubid.Format{secevsubid.FormatEmail, secevsubid.FormatIssuerSubject},
+	}
+	mux.Handle(secevsubid.WellKnownSSFConfigurationPath+"/issuer1", secevsubid.NewTransmitterConfigurationHandler(cfg))
+
+	got, err := secevsubid.FetchTransmitterConfiguration(context.Background(), nil, cfg.Issuer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("FetchTransmitterConfiguration() got = %v, want %v", got, cfg)
+	}
+
+	if _, err = secevsubid.FetchTransmitterConfiguration(context.Background(), nil, srv.URL+"/issuer2"); err == nil {
+		t.Error("error should be raised when configuration does not exist")
+	}
+}
+
+func TestFetchTransmitterConfigurationWithIssuerMismatch(t *testing.T) {
+	cfg := &secevsubid.TransmitterConfiguration{Issuer: "https://other.example.com"}
+	srv := httptest.NewServer(secevsubid.NewTransmitterConfigurationHandler(cfg))
+	defer srv.Close()
+
+	if _, err := secevsubid.FetchTransmitterConfiguration(context.Background(), nil, srv.URL); err == nil {
+		t.Error("error should be raised when issuer does not match")
+	}
+}
+
+func TestTransmitterConfiguration_NegotiateFormats(t *testing.T) {
+	preferred := []secevsubid.Format{secevsubid.FormatOpaque, secevsubid.FormatIssuerSubject, secevsubid.FormatEmail}
+	tests := []struct {
+		name      string
+		supported []secevsubid.Format
+		want      []secevsubid.Format
+	}{
+		{
+			name:      "declared",
+			supported: []secevsubid.Format{secevsubid.FormatEmail, secevsubid.FormatIssuerSubject},
+			want:      []secevsubid.Format{secevsubid.FormatIssuerSubject, secevsubid.FormatEmail},
+		},
+		{
+			name:      "not declared",
+			supported: nil,
+			want:      preferred,
+		},
+		{
+			name:      "aliases declared with simple formats",
+			supported: []secevsubid.Format{secevsubid.FormatAliases, secevsubid.FormatEmail},
+			want:      []secevsubid.Format{secevsubid.FormatEmail},
+		},
+		{
+			name:      "nothing in common",
+			supported: []secevsubid.Format{secevsubid.FormatDid},
+			want:      []secevsubid.Format{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &secevsubid.TransmitterConfiguration{Issuer: "https://tr.example.com", SubjectFormatsSupported: tt.supported}
+			if got := c.NegotiateFormats(preferred); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NegotiateFormats() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTransmitterConfiguration(t *testing.T) {
+	got, err := secevsubid.ParseTransmitterConfiguration([]byte(`{"issuer":"https://tr.example.com","delivery_methods_supported":["urn:ietf:rfc:8936"],"subject_formats_supported":["email","opaque"]}`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !got.SupportsDeliveryMethod(secevsubid.DeliveryMethodPoll) || got.SupportsDeliveryMethod(secevsubid.DeliveryMethodPush) {
+		t.Errorf("SupportsDeliveryMethod() got unexpected result for %v", got.DeliveryMethodsSupported)
+	}
+	if !got.SupportsFormat(secevsubid.FormatOpaque) || got.SupportsFormat(secevsubid.FormatDid) {
+		t.Errorf("SupportsFormat() got unexpected result for %v", got.SubjectFormatsSupported)
+	}
+
+	if _, err = secevsubid.ParseTransmitterConfiguration([]byte(`{"jwks_uri":"https://tr.example.com/jwks.json"}`)); err == nil {
+		t.Error("error should be raised when issuer is empty")
+	}
+}
@@ -0,0 +1,688 @@
+package secevsubid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// StreamState is the value of "status" of StreamStatus.
+type StreamState string
+
+const (
+	// StreamStateEnabled means that the transmitter transmits events over the stream.
+	StreamStateEnabled = StreamState("enabled")
+	// StreamStatePaused means that the transmitter holds events and transmits them after the stream is enabled.
+	StreamStatePaused = StreamState("paused")
+	// StreamStateDisabled means that the transmitter does not transmit events and does not hold them.
+	StreamStateDisabled = StreamState("disabled")
+)
+
+// StreamDelivery is "delivery" member of StreamConfiguration.
+type StreamDelivery struct {
+	// Method is the delivery method URI, e.g. DeliveryMethodPoll.
+	Method string `json:"method"`
+	// EndpointUrl is the URL of push endpoint for push delivery, or poll endpoint for poll delivery.
+	EndpointUrl string `json:"endpoint_url,omitempty"`
+	// AuthorizationHeader is the value of "Authorization" header the transmitter sends to push endpoint.
+	AuthorizationHeader string `json:"authorization_header,omitempty"`
+}
+
+// StreamConfiguration is the configuration of SSF event stream.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-stream-configuration
+type StreamConfiguration struct {
+	// StreamId is the identifier of the stream assigned by the transmitter.
+	StreamId string `json:"stream_id"`
+	// Issuer is "iss" value of SETs transmitted over the stream.
+	Issuer string `json:"iss,omitempty"`
+	// Audience is "aud" value of SETs transmitted over the stream. It is a string or an array of strings in JSON.
+	Audience Audience `json:"aud,omitempty"`
+	// EventsSupported is the list of event types the transmitter supports.
+	EventsSupported []string `json:"events_supported,omitempty"`
+	// EventsRequested is the list of event types the receiver requests.
+	EventsRequested []string `json:"events_requested,omitempty"`
+	// EventsDelivered is the list of event types transmitted over the stream.
+	EventsDelivered []string `json:"events_delivered,omitempty"`
+	// Delivery is the delivery method of the stream.
+	Delivery *StreamDelivery `json:"delivery,omitempty"`
+	// MinVerificationInterval is the minimum interval in seconds between verification requests.
+	MinVerificationInterval int `json:"min_verification_interval,omitempty"`
+	// Description is the description of the stream.
+	Description string `json:"description,omitempty"`
+}
+
+// StreamStatus is the status of SSF event stream.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-stream-status
+type StreamStatus struct {
+	// StreamId is the identifier of the stream.
+	StreamId string `json:"stream_id"`
+	// Status is the state of the stream.
+	Status StreamState `json:"status"`
+	// Reason is the reason of the status.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Validate values held and returns an error if there is a problem.
+func (s *StreamStatus) Validate() error {
+	if s.StreamId == "" {
+		return ErrEmptyStreamId
+	}
+
+	switch s.Status {
+	case StreamStateEnabled, StreamStatePaused, StreamStateDisabled:
+		return nil
+	}
+
+	return ErrInvalidStreamStatus
+}
+
+// StreamSubjectRequest is the request body of add subject and remove subject endpoints.
+// The subject is decoded via DecodeJSON, so an invalid subject identifier results in an error.
+type StreamSubjectRequest struct {
+	// StreamId is the identifier of the stream.
+	StreamId string `json:"stream_id"`
+	// Subject is the subject to add or remove.
+	Subject *Wrapper `json:"subject"`
+	// Verified indicates whether the receiver has verified the subject. It's used by add subject endpoint only.
+	Verified *bool `json:"verified,omitempty"`
+}
+
+// StreamStore stores streams and their subjects for StreamManager.
+type StreamStore interface {
+	// CreateStream stores new stream with enabled status.
+	CreateStream(ctx context.Context, c *StreamConfiguration) error
+	// GetStream returns the stream. If it does not exist, ErrStreamNotFound is returned.
+	GetStream(ctx context.Context, streamId string) (*StreamConfiguration, error)
+	// ListStreams returns all streams.
+	ListStreams(ctx context.Context) ([]*StreamConfiguration, error)
+	// UpdateStream replaces the stream. If it does not exist, ErrStreamNotFound is returned.
+	UpdateStream(ctx context.Context, c *StreamConfiguration) error
+	// DeleteStream deletes the stream with its status and subjects. If it does not exist, ErrStreamNotFound is returned.
+	DeleteStream(ctx context.Context, streamId string) error
+	// GetStatus returns the status of the stream. If it does not exist, ErrStreamNotFound is returned.
+	GetStatus(ctx context.Context, streamId string) (*StreamStatus, error)
+	// UpdateStatus replaces the status of the stream. If it does not exist, ErrStreamNotFound is returned.
+	UpdateStatus(ctx context.Context, s *StreamStatus) error
+	// AddSubject adds the subject to the stream. Adding the same subject again is not an error.
+	AddSubject(ctx context.Context, streamId string, subject SubjectIdentifier, verified bool) error
+	// RemoveSubject removes the subject from the stream. If it does not exist, ErrSubjectNotFound is returned.
+	RemoveSubject(ctx context.Context, streamId string, subject SubjectIdentifier) error
+	// Subjects returns subjects added to the stream.
+	Subjects(ctx context.Context, streamId string) ([]SubjectIdentifier, error)
+}
+
+type memoryStream struct {
+	config   StreamConfiguration
+	status   StreamStatus
+	subjects []SubjectIdentifier
+}
+
+// MemoryStreamStore is an in-memory implementation of StreamStore.
+// The zero value is an empty store ready to use.
+type MemoryStreamStore struct {
+	mu      sync.RWMutex
+	streams map[string]*memoryStream
+}
+
+// NewMemoryStreamStore creates new instance of MemoryStreamStore.
+func NewMemoryStreamStore() *MemoryStreamStore {
+	return &MemoryStreamStore{streams: make(map[string]*memoryStream)}
+}
+
+// CreateStream implements StreamStore.
+func (s *MemoryStreamStore) CreateStream(_ context.Context, c *StreamConfiguration) error {
+	if c.StreamId == "" {
+		return ErrEmptyStreamId
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.streams[c.StreamId]; ok {
+		return ErrStreamExists
+	}
+	if s.streams == nil {
+		s.streams = make(map[string]*memoryStream)
+	}
+	s.streams[c.StreamId] = &memoryStream{
+		config: *c,
+		status: StreamStatus{StreamId: c.StreamId, Status: StreamStateEnabled},
+	}
+	return nil
+}
+
+// GetStream implements StreamStore.
+func (s *MemoryStreamStore) GetStream(_ context.Context, streamId string) (*StreamConfiguration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st, ok := s.streams[streamId]
+	if !ok {
+		return nil, ErrStreamNotFound
+	}
+	c := st.config
+	return &c, nil
+}
+
+// ListStreams implements StreamStore.
+func (s *MemoryStreamStore) ListStreams(_ context.Context) ([]*StreamConfiguration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cs := make([]*StreamConfiguration, 0, len(s.streams))
+	for _, st := range s.streams {
+		c := st.config
+		cs = append(cs, &c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].StreamId < cs[j].StreamId })
+	return cs, nil
+}
+
+// UpdateStream implements StreamStore.
+func (s *MemoryStreamStore) UpdateStream(_ context.Context, c *StreamConfiguration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[c.StreamId]
+	if !ok {
+		return ErrStreamNotFound
+	}
+	st.config = *c
+	return nil
+}
+
+// DeleteStream implements StreamStore.
+func (s *MemoryStreamStore) DeleteStream(_ context.Context, streamId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.streams[streamId]; !ok {
+		return ErrStreamNotFound
+	}
+	delete(s.streams, streamId)
+	return nil
+}
+
+// GetStatus implements StreamStore.
+func (s *MemoryStreamStore) GetStatus(_ context.Context, streamId string) (*StreamStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st, ok := s.streams[streamId]
+	if !ok {
+		return nil, ErrStreamNotFound
+	}
+	status := st.status
+	return &status, nil
+}
+
+// UpdateStatus implements StreamStore.
+func (s *MemoryStreamStore) UpdateStatus(_ context.Context, status *StreamStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[status.StreamId]
+	if !ok {
+		return ErrStreamNotFound
+	}
+	st.status = *status
+	return nil
+}
+
+// AddSubject implements StreamStore.
+func (s *MemoryStreamStore) AddSubject(_ context.Context, streamId string, subject SubjectIdentifier, _ bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[streamId]
+	if !ok {
+		return ErrStreamNotFound
+	}
+	if indexOfSubject(st.subjects, subject) < 0 {
+		st.subjects = append(st.subjects, subject)
+	}
+	return nil
+}
+
+// RemoveSubject implements StreamStore.
+func (s *MemoryStreamStore) RemoveSubject(_ context.Context, streamId string, subject SubjectIdentifier) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[streamId]
+	if !ok {
+		return ErrStreamNotFound
+	}
+	i := indexOfSubject(st.subjects, subject)
+	if i < 0 {
+		return ErrSubjectNotFound
+	}
+	st.subjects = append(st.subjects[:i], st.subjects[i+1:]...)
+	return nil
+}
+
+// Subjects implements StreamStore.
+func (s *MemoryStreamStore) Subjects(_ context.Context, streamId string) ([]SubjectIdentifier, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st, ok := s.streams[streamId]
+	if !ok {
+		return nil, ErrStreamNotFound
+	}
+	ids := make([]SubjectIdentifier, len(st.subjects))
+	_ = copy(ids, st.subjects)
+	return ids, nil
+}
+
+func indexOfSubject(ids []SubjectIdentifier, subject SubjectIdentifier) int {
+	for i, v := range ids {
+		if v.Format() == subject.Format() && reflect.DeepEqual(v, subject) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// StreamManager serves SSF stream management API backed by StreamStore on the transmitter side.
+// Authentication of the receiver is not handled, so wrap handlers with your own middleware.
+type StreamManager struct {
+	// Store stores streams.
+	Store StreamStore
+	// Issuer is "iss" value of streams created by the manager.
+	Issuer string
+	// EventsSupported is the list of event types the transmitter supports.
+	EventsSupported []string
+	// NewStreamId generates the identifier of new stream. If nil, random hex string is used.
+	NewStreamId func() (string, error)
+	// Deliver transmits the SET over the stream, e.g. by push delivery or by enqueueing to EventQueue for poll delivery.
+	// It's used for verification events and stream updated events. If nil, these events are not transmitted.
+	Deliver func(ctx context.Context, c *StreamConfiguration, set *SecurityEventToken) error
+}
+
+// NewStreamManager creates new instance of StreamManager.
+func NewStreamManager(store StreamStore, issuer string, eventsSupported []string) *StreamManager {
+	return &StreamManager{
+		Store:           store,
+		Issuer:          issuer,
+		EventsSupported: eventsSupported,
+	}
+}
+
+func (m *StreamManager) newStreamId() (string, error) {
+	if m.NewStreamId != nil {
+		return m.NewStreamId()
+	}
+
+	return randomHex(16)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (m *StreamManager) eventsDelivered(requested []string) []string {
+	var es []string
+	for _, r := range requested {
+		for _, s := range m.EventsSupported {
+			if r == s {
+				es = append(es, r)
+				break
+			}
+		}
+	}
+
+	return es
+}
+
+// ConfigurationHandler returns http.Handler of the stream configuration endpoint.
+// POST creates, GET reads (all streams if "stream_id" query is omitted), PUT replaces, PATCH updates and DELETE deletes the stream.
+func (m *StreamManager) ConfigurationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			m.createStream(w, r)
+		case http.MethodGet:
+			m.getStream(w, r)
+		case http.MethodPut, http.MethodPatch:
+			m.updateStream(w, r)
+		case http.MethodDelete:
+			m.deleteStream(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, PUT, PATCH, DELETE")
+			writeJSONError(w, http.StatusMethodNotAllowed, SETErrInvalidRequest, "method not allowed")
+		}
+	})
+}
+
+func (m *StreamManager) createStream(w http.ResponseWriter, r *http.Request) {
+	var req StreamConfiguration
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+		return
+	}
+
+	id, err := m.newStreamId()
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	c := &StreamConfiguration{
+		StreamId:        id,
+		Issuer:          m.Issuer,
+		Audience:        req.Audience,
+		EventsSupported: m.EventsSupported,
+		EventsRequested: req.EventsRequested,
+		EventsDelivered: m.eventsDelivered(req.EventsRequested),
+		Delivery:        req.Delivery,
+		Description:     req.Description,
+	}
+	if c.Delivery == nil {
+		c.Delivery = &StreamDelivery{Method: DeliveryMethodPoll}
+	}
+	if err = m.Store.CreateStream(r.Context(), c); err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (m *StreamManager) getStream(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("stream_id")
+	if id == "" {
+		cs, err := m.Store.ListStreams(r.Context())
+		if err != nil {
+			writeStreamError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, cs)
+		return
+	}
+
+	c, err := m.Store.GetStream(r.Context(), id)
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (m *StreamManager) updateStream(w http.ResponseWriter, r *http.Request) {
+	var req StreamConfiguration
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+		return
+	}
+	if req.StreamId == "" {
+		writeStreamError(w, ErrEmptyStreamId)
+		return
+	}
+
+	c, err := m.Store.GetStream(r.Context(), req.StreamId)
+	if err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	if r.Method == http.MethodPut || req.EventsRequested != nil {
+		c.EventsRequested = req.EventsRequested
+		c.EventsDelivered = m.eventsDelivered(req.EventsRequested)
+	}
+	if r.Method == http.MethodPut || req.Delivery != nil {
+		c.Delivery = req.Delivery
+	}
+	if r.Method == http.MethodPut || req.Description != "" {
+		c.Description = req.Description
+	}
+	if r.Method == http.MethodPut || len(req.Audience) > 0 {
+		c.Audience = req.Audience
+	}
+	if err = m.Store.UpdateStream(r.Context(), c); err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (m *StreamManager) deleteStream(w http.ResponseWriter, r *http.Request) {
+	if err := m.Store.DeleteStream(r.Context(), r.URL.Query().Get("stream_id")); err != nil {
+		writeStreamError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StatusHandler returns http.Handler of the stream status endpoint.
+// GET reads and POST updates the status of the stream.
+func (m *StreamManager) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s, err := m.Store.GetStatus(r.Context(), r.URL.Query().Get("stream_id"))
+			if err != nil {
+				writeStreamError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, s)
+		case http.MethodPost:
+			var s StreamStatus
+			if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+				writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+				return
+			}
+			if err := s.Validate(); err != nil {
+				writeStreamError(w, err)
+				return
+			}
+			if err := m.Store.UpdateStatus(r.Context(), &s); err != nil {
+				writeStreamError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, &s)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeJSONError(w, http.StatusMethodNotAllowed, SETErrInvalidRequest, "method not allowed")
+		}
+	})
+}
+
+// AddSubjectHandler returns http.Handler of the add subject endpoint.
+func (m *StreamManager) AddSubjectHandler() http.Handler {
+	return m.subjectHandler(http.StatusOK, func(ctx context.Context, req *StreamSubjectRequest) error {
+		verified := true
+		if req.Verified != nil {
+			verified = *req.Verified
+		}
+		return m.Store.AddSubject(ctx, req.StreamId, req.Subject.Value(), verified)
+	})
+}
+
+// RemoveSubjectHandler returns http.Handler of the remove subject endpoint.
+func (m *StreamManager) RemoveSubjectHandler() http.Handler {
+	return m.subjectHandler(http.StatusNoContent, func(ctx context.Context, req *StreamSubjectRequest) error {
+		return m.Store.RemoveSubject(ctx, req.StreamId, req.Subject.Value())
+	})
+}
+
+func (m *StreamManager) subjectHandler(status int, f func(ctx context.Context, req *StreamSubjectRequest) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, SETErrInvalidRequest, "method not allowed")
+			return
+		}
+
+		var req StreamSubjectRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+			return
+		}
+		if req.StreamId == "" {
+			writeStreamError(w, ErrEmptyStreamId)
+			return
+		}
+		if req.Subject == nil || req.Subject.Value() == nil {
+			writeStreamError(w, ErrNoSubject)
+			return
+		}
+
+		if err := f(r.Context(), &req); err != nil {
+			writeStreamError(w, err)
+			return
+		}
+
+		w.WriteHeader(status)
+	})
+}
+
+func writeStreamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrStreamNotFound), errors.Is(err, ErrSubjectNotFound):
+		writeJSONError(w, http.StatusNotFound, SETErrInvalidRequest, err.Error())
+	case errors.Is(err, ErrStreamExists):
+		writeJSONError(w, http.StatusConflict, SETErrInvalidRequest, err.Error())
+	case errors.Is(err, ErrEmptyStreamId), errors.Is(err, ErrInvalidStreamStatus), errors.Is(err, ErrNoSubject):
+		writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+	default:
+		writeJSONError(w, http.StatusInternalServerError, SETErrInvalidRequest, err.Error())
+	}
+}
+
+// StreamClient calls SSF stream management API on the receiver side.
+// Endpoints are taken from TransmitterConfiguration.
+type StreamClient struct {
+	// Configuration is the transmitter configuration holding endpoints.
+	Configuration *TransmitterConfiguration
+	// HTTPClient is used for requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Header is added to each request, e.g. "Authorization".
+	Header http.Header
+}
+
+// NewStreamClient creates new instance of StreamClient.
+func NewStreamClient(c *TransmitterConfiguration) *StreamClient {
+	return &StreamClient{Configuration: c}
+}
+
+func (c *StreamClient) do(ctx context.Context, method string, endpoint string, query url.Values, in interface{}, out interface{}) error {
+	if endpoint == "" {
+		return ErrNoEndpoint
+	}
+
+	if len(query) > 0 {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		u.RawQuery = query.Encode()
+		endpoint = u.String()
+	}
+
+	err := doJSON(ctx, c.HTTPClient, method, endpoint, c.Header, in, out)
+	var se *StatusError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+		if se.Description == ErrSubjectNotFound.Error() {
+			return ErrSubjectNotFound
+		}
+		return ErrStreamNotFound
+	}
+	return err
+}
+
+// CreateStream creates new stream and returns the configuration assigned by the transmitter.
+func (c *StreamClient) CreateStream(ctx context.Context, config *StreamConfiguration) (*StreamConfiguration, error) {
+	res := &StreamConfiguration{}
+	if err := c.do(ctx, http.MethodPost, c.Configuration.ConfigurationEndpoint, nil, config, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetStream returns the configuration of the stream.
+func (c *StreamClient) GetStream(ctx context.Context, streamId string) (*StreamConfiguration, error) {
+	res := &StreamConfiguration{}
+	if err := c.do(ctx, http.MethodGet, c.Configuration.ConfigurationEndpoint, url.Values{"stream_id": {streamId}}, nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListStreams returns configurations of all streams of the receiver.
+func (c *StreamClient) ListStreams(ctx context.Context) ([]*StreamConfiguration, error) {
+	var res []*StreamConfiguration
+	if err := c.do(ctx, http.MethodGet, c.Configuration.ConfigurationEndpoint, nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdateStream updates members of the stream which are set in the argument (PATCH).
+func (c *StreamClient) UpdateStream(ctx context.Context, config *StreamConfiguration) (*StreamConfiguration, error) {
+	res := &StreamConfiguration{}
+	if err := c.do(ctx, http.MethodPatch, c.Configuration.ConfigurationEndpoint, nil, config, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReplaceStream replaces receiver-supplied members of the stream with the argument (PUT).
+func (c *StreamClient) ReplaceStream(ctx context.Context, config *StreamConfiguration) (*StreamConfiguration, error) {
+	res := &StreamConfiguration{}
+	if err := c.do(ctx, http.MethodPut, c.Configuration.ConfigurationEndpoint, nil, config, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteStream deletes the stream.
+func (c *StreamClient) DeleteStream(ctx context.Context, streamId string) error {
+	return c.do(ctx, http.MethodDelete, c.Configuration.ConfigurationEndpoint, url.Values{"stream_id": {streamId}}, nil, nil)
+}
+
+// GetStatus returns the status of the stream.
+func (c *StreamClient) GetStatus(ctx context.Context, streamId string) (*StreamStatus, error) {
+	res := &StreamStatus{}
+	if err := c.do(ctx, http.MethodGet, c.Configuration.StatusEndpoint, url.Values{"stream_id": {streamId}}, nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdateStatus updates the status of the stream.
+func (c *StreamClient) UpdateStatus(ctx context.Context, status *StreamStatus) (*StreamStatus, error) {
+	if err := status.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := &StreamStatus{}
+	if err := c.do(ctx, http.MethodPost, c.Configuration.StatusEndpoint, nil, status, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AddSubject adds the subject to the stream.
+func (c *StreamClient) AddSubject(ctx context.Context, streamId string, subject SubjectIdentifier, verified bool) error {
+	if err := subject.Validate(); err != nil {
+		return err
+	}
+
+	req := &StreamSubjectRequest{StreamId: streamId, Subject: NewWrapper(subject), Verified: &verified}
+	return c.do(ctx, http.MethodPost, c.Configuration.AddSubjectEndpoint, nil, req, nil)
+}
+
+// RemoveSubject removes the subject from the stream.
+func (c *StreamClient) RemoveSubject(ctx context.Context, streamId string, subject SubjectIdentifier) error {
+	if err := subject.Validate(); err != nil {
+		return err
+	}
+
+	req := &StreamSubjectRequest{StreamId: streamId, Subject: NewWrapper(subject)}
+	return c.do(ctx, http.MethodPost, c.Configuration.RemoveSubjectEndpoint, nil, req, nil)
+}
@@ -0,0 +1,262 @@
+package secevsubid_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pinzolo/secevsubid"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestStreamServer(t *testing.T) (*secevsubid.MemoryStreamStore, *secevsubid.StreamClient, func()) {
+	t.Helper()
+	store := secevsubid.NewMemoryStreamStore()
+	m := secevsubid.NewStreamManager(store, "https://tr.example.com", []string{secevsubid.EventTypeAccountDisabled, secevsubid.EventTypeSessionRevoked})
+	n := 0
+	m.NewStreamId = func() (string, error) {
+		n++
+		return "stream" + strconv.Itoa(n), nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/ssf/stream", m.ConfigurationHandler())
+	mux.Handle("/ssf/status", m.StatusHandler())
+	mux.Handle("/ssf/subjects:add", m.AddSubjectHandler())
+	mux.Handle("/ssf/subjects:remove", m.RemoveSubjectHandler())
+	srv := httptest.NewServer(mux)
+
+	c := secevsubid.NewStreamClient(&secevsubid.TransmitterConfiguration{
+		Issuer:                "https://tr.example.com",
+		ConfigurationEndpoint: srv.URL + "/ssf/stream",
+		StatusEndpoint:        srv.URL + "/ssf/status",
+		AddSubjectEndpoint:    srv.URL + "/ssf/subjects:add",
+		RemoveSubjectEndpoint: srv.URL + "/ssf/subjects:remove",
+	})
+	return store, c, srv.Close
+}
+
+func TestStreamClient_Configuration(t *testing.T) {
+	_, c, closeFunc := newTestStreamServer(t)
+	defer closeFunc()
+	ctx := context.Background()
+
+	created, err := c.CreateStream(ctx, &secevsubid.StreamConfiguration{
+		EventsRequested: []string{secevsubid.EventTypeAccountDisabled, secevsubid.EventTypeCredentialChange},
+		Delivery:        &secevsubid.StreamDelivery{Method: secevsubid.DeliveryMethodPush, EndpointUrl: "https://receiver.example.com/events"},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if created.StreamId != "stream1" || created.Issuer != "https://tr.example.com" {
+		t.Errorf("CreateStream() got = %v, want stream1 issued by transmitter", created)
+	}
+	if !reflect.DeepEqual(created.EventsDelivered, []string{secevsubid.EventTypeAccountDisabled}) {
+		t.Errorf("CreateStream() events_delivered = %v, want intersection of requested and supported", created.EventsDelivered)
+	}
+
+	got, err := c.GetStream(ctx, created.StreamId)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got, created) {
+		t.Errorf("GetStream() got = %v, want %v", got, created)
+	}
+
+	updated, err := c.UpdateStream(ctx, &secevsubid.StreamConfiguration{StreamId: created.StreamId, Description: "updated"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if updated.Description != "updated" || updated.Delivery.Method != secevsubid.DeliveryMethodPush {
+		t.Errorf("UpdateStream() got = %v, want only description to be updated", updated)
+	}
+
+	replaced, err := c.ReplaceStream(ctx, &secevsubid.StreamConfiguration{
+		StreamId: created.StreamId,
+		Delivery: &secevsubid.StreamDelivery{Method: secevsubid.DeliveryMethodPoll},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if replaced.Description != "" || replaced.EventsDelivered != nil {
+		t.Errorf("ReplaceStream() got = %v, want receiver-supplied members to be replaced", replaced)
+	}
+
+	list, err := c.ListStreams(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list) != 1 {
+		t.Errorf("ListStreams() got = %v, want 1 stream", list)
+	}
+
+	if err = c.DeleteStream(ctx, created.StreamId); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = c.GetStream(ctx, created.StreamId); err != secevsubid.ErrStreamNotFound {
+		t.Errorf("GetStream() error = %v, wantErr %v", err, secevsubid.ErrStreamNotFound)
+	}
+}
+
+func TestStreamClient_Status(t *testing.T) {
+	_, c, closeFunc := newTestStreamServer(t)
+	defer closeFunc()
+	ctx := context.Background()
+
+	created, _ := c.CreateStream(ctx, &secevsubid.StreamConfiguration{})
+	s, err := c.GetStatus(ctx, created.StreamId)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if s.Status != secevsubid.StreamStateEnabled {
+		t.Errorf("GetStatus() got = %v, want enabled", s)
+	}
+
+	s, err = c.UpdateStatus(ctx, &secevsubid.StreamStatus{StreamId: created.StreamId, Status: secevsubid.StreamStatePaused, Reason: "maintenance"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if s.Status != secevsubid.StreamStatePaused {
+		t.Errorf("UpdateStatus() got = %v, want paused", s)
+	}
+
+	if _, err = c.UpdateStatus(ctx, &secevsubid.StreamStatus{StreamId: created.StreamId, Status: "stopped"}); err != secevsubid.ErrInvalidStreamStatus {
+		t.Errorf("UpdateStatus() error = %v, wantErr %v", err, secevsubid.ErrInvalidStreamStatus)
+	}
+	if _, err = c.GetStatus(ctx, "unknown"); err != secevsubid.ErrStreamNotFound {
+		t.Errorf("GetStatus() error = %v, wantErr %v", err, secevsubid.ErrStreamNotFound)
+	}
+}
+
+func TestStreamClient_Subjects(t *testing.T) {
+	store, c, closeFunc := newTestStreamServer(t)
+	defer closeFunc()
+	ctx := context.Background()
+
+	created, _ := c.CreateStream(ctx, &secevsubid.StreamConfiguration{})
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+
+	if err := c.AddSubject(ctx, created.StreamId, email, true); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := c.AddSubject(ctx, created.StreamId, issSub, false); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := c.AddSubject(ctx, created.StreamId, email, true); err != nil {
+		t.Error(err)
+		return
+	}
+	got, _ := store.Subjects(ctx, created.StreamId)
+	if !reflect.DeepEqual(got, []secevsubid.SubjectIdentifier{email, issSub}) {
+		t.Errorf("Subjects() got = %v, want %v", got, []secevsubid.SubjectIdentifier{email, issSub})
+	}
+
+	if err := c.RemoveSubject(ctx, created.StreamId, email); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := c.RemoveSubject(ctx, created.StreamId, email); err != secevsubid.ErrSubjectNotFound {
+		t.Errorf("RemoveSubject() error = %v, wantErr %v", err, secevsubid.ErrSubjectNotFound)
+	}
+	if err := c.AddSubject(ctx, "unknown", email, true); err != secevsubid.ErrStreamNotFound {
+		t.Errorf("AddSubject() error = %v, wantErr %v", err, secevsubid.ErrStreamNotFound)
+	}
+}
+
+func TestStreamManager_AddSubjectHandlerWithInvalidSubject(t *testing.T) {
+	store := secevsubid.NewMemoryStreamStore()
+	_ = store.CreateStream(context.Background(), &secevsubid.StreamConfiguration{StreamId: "stream1"})
+	h := secevsubid.NewStreamManager(store, "https://tr.example.com", nil).AddSubjectHandler()
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{
+			name: "valid",
+			body: `{"stream_id":"stream1","subject":{"format":"email","email":"user@example.com"}}`,
+			want: http.StatusOK,
+		},
+		{
+			name: "invalid subject",
+			body: `{"stream_id":"stream1","subject":{"format":"email"}}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "unknown format",
+			body: `{"stream_id":"stream1","subject":{"format":"unknown","id":"1"}}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "no subject",
+			body: `{"stream_id":"stream1"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "no stream_id",
+			body: `{"subject":{"format":"email","email":"user@example.com"}}`,
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ssf/subjects:add", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamConfiguration_AudienceArray(t *testing.T) {
+	b := []byte(`{"stream_id":"stream1","iss":"https://tr.example.com","aud":["https://a.example.com","https://b.example.com"]}`)
+	var c secevsubid.StreamConfiguration
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Error(err)
+		return
+	}
+	want := secevsubid.Audience{"https://a.example.com", "https://b.example.com"}
+	if !reflect.DeepEqual(c.Audience, want) {
+		t.Errorf("Audience = %v, want %v", c.Audience, want)
+	}
+
+	set, err := secevsubid.NewVerificationSET(&c, "state")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(set.Audience, want) {
+		t.Errorf("SET Audience = %v, want %v", set.Audience, want)
+	}
+}
+
+func TestMemoryStreamStore_ZeroValue(t *testing.T) {
+	ctx := context.Background()
+	s := &secevsubid.MemoryStreamStore{}
+	if _, err := s.GetStream(ctx, "stream1"); err != secevsubid.ErrStreamNotFound {
+		t.Errorf("GetStream() error = %v, wantErr %v", err, secevsubid.ErrStreamNotFound)
+	}
+	if err := s.CreateStream(ctx, &secevsubid.StreamConfiguration{StreamId: "stream1"}); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := s.GetStream(ctx, "stream1"); err != nil {
+		t.Error(err)
+	}
+}
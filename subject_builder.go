@@ -0,0 +1,105 @@
+package secevsubid
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// SubjectBuilder chooses identifiers of a user to be sent to a receiver as the subject of events.
+type SubjectBuilder struct {
+	// Formats is the list of formats the receiver supports in preference order.
+	// If empty, all formats are acceptable and the order of the known identifiers is kept.
+	Formats []Format
+	// Denied is the list of formats never sent to the receiver, e.g. FormatEmail for privacy reasons.
+	Denied []Format
+	// Aliases indicates whether all acceptable identifiers are sent as AliasesIdentifier.
+	// If false, only the most preferred identifier is sent.
+	Aliases bool
+}
+
+// NewSubjectBuilder creates new instance of SubjectBuilder with formats in preference order.
+func NewSubjectBuilder(formats ...Format) *SubjectBuilder {
+	return &SubjectBuilder{Formats: formats}
+}
+
+// Capabilities returns Capabilities of the receiver described by Formats and Aliases.
+func (b *SubjectBuilder) Capabilities() Capabilities {
+	return Capabilities{Formats: b.Formats, Aliases: b.Aliases}
+}
+
+// Build returns the subject built from the known identifiers of the user.
+// AliasesIdentifier in the arguments is expanded to its members, and each member is converted by Negotiate with Capabilities,
+// so that ComplexIdentifier is reduced to its "user" member. Identifiers in Denied formats are excluded after the conversion.
+// If Aliases is true and two or more identifiers are acceptable, it returns AliasesIdentifier.
+// If no identifier is acceptable, it returns an error wrapping ErrNoAcceptableSubject.
+func (b *SubjectBuilder) Build(known ...SubjectIdentifier) (SubjectIdentifier, error) {
+	ids, err := b.acceptable(known)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: none of %d identifiers is acceptable (formats: %v, denied: %v)", ErrNoAcceptableSubject, len(known), b.Formats, b.Denied)
+	}
+
+	if !b.Aliases || len(ids) == 1 {
+		return ids[0], nil
+	}
+
+	return NewAliasesIdentifier(ids...)
+}
+
+func (b *SubjectBuilder) acceptable(known []SubjectIdentifier) ([]SubjectIdentifier, error) {
+	// Members of aliases are negotiated one by one, so aliases is not accepted here.
+	caps := b.Capabilities()
+	caps.Aliases = false
+	var ids []SubjectIdentifier
+	seen := make(map[string]bool)
+	for _, id := range known {
+		if id == nil {
+			continue
+		}
+
+		members := []SubjectIdentifier{id}
+		if a, ok := id.(AliasesIdentifier); ok {
+			members = a.Identifiers()
+		}
+		for _, m := range members {
+			m, err := Negotiate(m, caps)
+			if errors.Is(err, ErrNoAcceptableSubject) || (err == nil && containsFormat(b.Denied, m.Format())) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if err = m.Validate(); err != nil {
+				return nil, err
+			}
+			k, err := identifierKey(m)
+			if err != nil {
+				return nil, err
+			}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			ids = append(ids, m)
+		}
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		return b.rank(ids[i].Format()) < b.rank(ids[j].Format())
+	})
+	return ids, nil
+}
+
+func (b *SubjectBuilder) rank(f Format) int {
+	for i, v := range b.Formats {
+		if v == f {
+			return i
+		}
+	}
+
+	return len(b.Formats)
+}
@@ -0,0 +1,89 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestSubjectBuilder_Build(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	known, _ := secevsubid.NewAliasesIdentifier(email, phone)
+	issSubAndEmail, _ := secevsubid.NewAliasesIdentifier(issSub, email)
+	all, _ := secevsubid.NewAliasesIdentifier(issSub, email, phone)
+	cplx, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: email, Tenant: issSub})
+
+	tests := []struct {
+		name    string
+		builder *secevsubid.SubjectBuilder
+		known   []secevsubid.SubjectIdentifier
+		want    secevsubid.SubjectIdentifier
+		wantErr error
+	}{
+		{
+			name:    "most preferred",
+			builder: secevsubid.NewSubjectBuilder(secevsubid.FormatIssuerSubject, secevsubid.FormatEmail),
+			known:   []secevsubid.SubjectIdentifier{email, issSub},
+			want:    issSub,
+		},
+		{
+			name:    "aliases in preference order",
+			builder: &secevsubid.SubjectBuilder{Formats: []secevsubid.Format{secevsubid.FormatIssuerSubject, secevsubid.FormatEmail}, Aliases: true},
+			known:   []secevsubid.SubjectIdentifier{known, issSub},
+			want:    issSubAndEmail,
+		},
+		{
+			name:    "no formats accepts all",
+			builder: &secevsubid.SubjectBuilder{Aliases: true},
+			known:   []secevsubid.SubjectIdentifier{issSub, known, email},
+			want:    all,
+		},
+		{
+			name:    "single acceptable identifier is not aliases",
+			builder: &secevsubid.SubjectBuilder{Formats: []secevsubid.Format{secevsubid.FormatEmail}, Aliases: true},
+			known:   []secevsubid.SubjectIdentifier{email, issSub},
+			want:    email,
+		},
+		{
+			name:    "denied",
+			builder: &secevsubid.SubjectBuilder{Formats: []secevsubid.Format{secevsubid.FormatEmail, secevsubid.FormatPhoneNumber}, Denied: []secevsubid.Format{secevsubid.FormatEmail}},
+			known:   []secevsubid.SubjectIdentifier{email, phone},
+			want:    phone,
+		},
+		{
+			name:    "complex is reduced to user",
+			builder: secevsubid.NewSubjectBuilder(secevsubid.FormatEmail),
+			known:   []secevsubid.SubjectIdentifier{cplx},
+			want:    email,
+		},
+		{
+			name:    "no acceptable",
+			builder: &secevsubid.SubjectBuilder{Formats: []secevsubid.Format{secevsubid.FormatEmail}, Denied: []secevsubid.Format{secevsubid.FormatEmail}},
+			known:   []secevsubid.SubjectIdentifier{email, phone},
+			wantErr: secevsubid.ErrNoAcceptableSubject,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build(tt.known...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubjectBuilder_Capabilities(t *testing.T) {
+	b := &secevsubid.SubjectBuilder{Formats: []secevsubid.Format{secevsubid.FormatEmail}, Denied: []secevsubid.Format{secevsubid.FormatOpaque}, Aliases: true}
+	want := secevsubid.Capabilities{Formats: []secevsubid.Format{secevsubid.FormatEmail}, Aliases: true}
+	if got := b.Capabilities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Capabilities() = %v, want %v", got, want)
+	}
+}
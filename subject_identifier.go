@@ -47,12 +47,12 @@ func NewWrapper(id SubjectIdentifier) *Wrapper {
 	return &Wrapper{v: id}
 }
 
+// extractStringValue returns the string value of the field.
+// Since decoded values come from untrusted input, a missing or non-string value is returned as empty string
+// and rejected by validation of each format instead of causing panic.
 var extractStringValue = func(m map[string]interface{}, name string) string {
-	s, ok := m[name]
-	if !ok {
-		return ""
-	}
-	return s.(string)
+	s, _ := m[name].(string)
+	return s
 }
 
 // DecodeJSON decodes to the appropriate SubjectIdentifier instance.
@@ -67,9 +67,35 @@ func DecodeJSON(b []byte) (SubjectIdentifier, error) {
 	return decodeIdentifier(m)
 }
 
+var complexMemberFields = []string{fieldUser, fieldDevice, fieldSession, fieldApplication, fieldTenant, fieldOrgUnit, fieldGroup}
+
+// identifierMap returns JSON representation of the identifier as map, which is the common form of other encodings.
+func identifierMap(id SubjectIdentifier) (map[string]interface{}, error) {
+	if id == nil {
+		return nil, ErrNoSubject
+	}
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
 func decodeIdentifier(m map[string]interface{}) (SubjectIdentifier, error) {
 	f, ok := m[fieldFormat].(string)
 	if !ok {
+		if isComplex(m) {
+			return decodeComplex(m)
+		}
 		return nil, ErrNoFormat
 	}
 
@@ -88,15 +114,24 @@ func decodeIdentifier(m map[string]interface{}) (SubjectIdentifier, error) {
 		return NewDidIdentifier(extractStringValue(m, fieldUrl))
 	case FormatUri:
 		return NewUriIdentifier(extractStringValue(m, fieldUri))
+	case FormatJwtId:
+		return NewJwtIdIdentifier(extractStringValue(m, fieldIssuer), extractStringValue(m, fieldJwtId))
+	case FormatSamlAssertionId:
+		return NewSamlAssertionIdIdentifier(extractStringValue(m, fieldSamlIssuer), extractStringValue(m, fieldAssertionId))
 	case FormatAliases:
 		return decodeAliases(m)
+	case FormatComplex:
+		return decodeComplex(m)
 	}
 
 	return nil, fmt.Errorf("unknown format: %s", f)
 }
 
 func decodeAliases(m map[string]interface{}) (SubjectIdentifier, error) {
-	vs := m[fieldIdentifiers].([]interface{})
+	vs, ok := m[fieldIdentifiers].([]interface{})
+	if !ok && m[fieldIdentifiers] != nil {
+		return nil, fmt.Errorf("not JSON array: %v", m[fieldIdentifiers])
+	}
 	if len(vs) == 0 {
 		return nil, ErrEmptyIdentifiers
 	}
@@ -131,3 +166,48 @@ func decodeAliases(m map[string]interface{}) (SubjectIdentifier, error) {
 
 	return id, nil
 }
+
+func isComplex(m map[string]interface{}) bool {
+	for _, name := range complexMemberFields {
+		if _, ok := m[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeComplex(m map[string]interface{}) (SubjectIdentifier, error) {
+	ids := make(map[string]SubjectIdentifier)
+	for _, name := range complexMemberFields {
+		v, ok := m[name]
+		if !ok {
+			continue
+		}
+
+		d, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("not JSON object: %v", v)
+		}
+		if _, ok = d[fieldFormat]; !ok && isComplex(d) {
+			return nil, ErrNestedComplex
+		}
+
+		id, err := decodeIdentifier(d)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[name] = id
+	}
+
+	return NewComplexIdentifier(ComplexMembers{
+		User:        ids[fieldUser],
+		Device:      ids[fieldDevice],
+		Session:     ids[fieldSession],
+		Application: ids[fieldApplication],
+		Tenant:      ids[fieldTenant],
+		OrgUnit:     ids[fieldOrgUnit],
+		Group:       ids[fieldGroup],
+	})
+}
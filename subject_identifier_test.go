@@ -2,6 +2,7 @@ package secevsubid_test
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/pinzolo/secevsubid"
 	"reflect"
 	"testing"
@@ -305,3 +306,112 @@ func TestWrapper_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapper_UnmarshalJSONWithTokenIdentifiers(t *testing.T) {
+	jwtId, _ := secevsubid.NewJwtIdIdentifier("https://idp.example.com/123456789/", "B70BA622-9515-4353-A866-823539EECBC8")
+	samlAssertionId, _ := secevsubid.NewSamlAssertionIdIdentifier("https://idp.example.com/123456789/", "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6")
+	tokenAliases, _ := secevsubid.NewAliasesIdentifier(jwtId, samlAssertionId)
+
+	tests := []struct {
+		name    string
+		json    string
+		want    secevsubid.SubjectIdentifier
+		wantErr bool
+	}{
+		{
+			name: "jwt id success",
+			json: `
+{
+  "format": "jwt_id",
+  "iss": "https://idp.example.com/123456789/",
+  "jti": "B70BA622-9515-4353-A866-823539EECBC8"
+}`,
+			want:    jwtId,
+			wantErr: false,
+		},
+		{
+			name: "saml assertion id success",
+			json: `
+{
+  "format": "saml_assertion_id",
+  "issuer": "https://idp.example.com/123456789/",
+  "assertion_id": "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6"
+}`,
+			want:    samlAssertionId,
+			wantErr: false,
+		},
+		{
+			name: "aliases with token identifiers success",
+			json: `
+{
+  "format": "aliases",
+  "identifiers": [
+    {
+      "format": "jwt_id",
+      "iss": "https://idp.example.com/123456789/",
+      "jti": "B70BA622-9515-4353-A866-823539EECBC8"
+    },
+    {
+      "format": "saml_assertion_id",
+      "issuer": "https://idp.example.com/123456789/",
+      "assertion_id": "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6"
+    }
+  ]
+}`,
+			want:    tokenAliases,
+			wantErr: false,
+		},
+		{
+			name: "jwt id without jti",
+			json: `
+{
+  "format": "jwt_id",
+  "iss": "https://idp.example.com/123456789/"
+}`,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &secevsubid.Wrapper{}
+			err := json.Unmarshal([]byte(tt.json), &w)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(w.Value(), tt.want) {
+				t.Errorf("UnmarshalJSON() = %v, want %v", w.Value(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONWithMalformedValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr error
+	}{
+		{name: "aliases without identifiers", json: `{"format":"aliases"}`, wantErr: secevsubid.ErrEmptyIdentifiers},
+		{name: "aliases with null identifiers", json: `{"format":"aliases","identifiers":null}`, wantErr: secevsubid.ErrEmptyIdentifiers},
+		{name: "aliases with string identifiers", json: `{"format":"aliases","identifiers":"email"}`},
+		{name: "aliases with non-object member", json: `{"format":"aliases","identifiers":[1]}`},
+		{name: "number value", json: `{"format":"email","email":1}`, wantErr: secevsubid.ErrEmptyEmail},
+		{name: "object value", json: `{"format":"iss_sub","iss":{},"sub":"145234573"}`, wantErr: secevsubid.ErrEmptyIssuer},
+		{name: "number format", json: `{"format":1}`, wantErr: secevsubid.ErrNoFormat},
+		{name: "number member format", json: `{"format":"aliases","identifiers":[{"format":1}]}`, wantErr: secevsubid.ErrNoFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := secevsubid.DecodeJSON([]byte(tt.json))
+			if err == nil {
+				t.Errorf("DecodeJSON() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package secevsubid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// textFields holds names of the fields joined with "/" in the text representation of each format.
+var textFields = map[Format][]string{
+	FormatAccount:         {fieldUri},
+	FormatEmail:           {fieldEmail},
+	FormatIssuerSubject:   {fieldIssuer, fieldSubject},
+	FormatOpaque:          {fieldId},
+	FormatPhoneNumber:     {fieldPhoneNumber},
+	FormatDid:             {fieldUrl},
+	FormatUri:             {fieldUri},
+	FormatJwtId:           {fieldIssuer, fieldJwtId},
+	FormatSamlAssertionId: {fieldSamlIssuer, fieldAssertionId},
+}
+
+// EncodeText returns the compact text representation of the identifier.
+//   - The format and values are joined with ":", e.g. "email:user@example.com".
+//   - Multiple values are joined with "/" in the order of the specification, e.g. "iss_sub:https%3A%2F%2Fissuer.example.com%2F/145234573".
+//   - Aliases Identifier is "aliases:[" + members joined with "," + "]".
+//   - Complex Subject is "complex:{" + "name=member" joined with "," + "}".
+//
+// Bytes in values other than ALPHA, DIGIT and "-._~@+" are percent-encoded, so that every valid identifier round-trips via DecodeText.
+func EncodeText(id SubjectIdentifier) (string, error) {
+	m, err := identifierMap(id)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeText(m)
+}
+
+func encodeText(m map[string]interface{}) (string, error) {
+	f, ok := m[fieldFormat].(string)
+	if !ok {
+		if !isComplex(m) {
+			return "", ErrNoFormat
+		}
+
+		var ss []string
+		for _, name := range complexMemberFields {
+			d, ok := m[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			s, err := encodeText(d)
+			if err != nil {
+				return "", err
+			}
+			ss = append(ss, name+"="+s)
+		}
+		return string(FormatComplex) + ":{" + strings.Join(ss, ",") + "}", nil
+	}
+
+	if Format(f) == FormatAliases {
+		vs, _ := m[fieldIdentifiers].([]interface{})
+		ss := make([]string, 0, len(vs))
+		for _, v := range vs {
+			d, ok := v.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("not JSON object: %v", v)
+			}
+			s, err := encodeText(d)
+			if err != nil {
+				return "", err
+			}
+			ss = append(ss, s)
+		}
+		return string(FormatAliases) + ":[" + strings.Join(ss, ",") + "]", nil
+	}
+
+	names, ok := textFields[Format(f)]
+	if !ok {
+		return "", fmt.Errorf("unknown format: %s", f)
+	}
+	vs := make([]string, len(names))
+	for i, name := range names {
+		vs[i] = escapeText(extractStringValue(m, name))
+	}
+	return f + ":" + strings.Join(vs, "/"), nil
+}
+
+// DecodeText decodes the text representation generated by EncodeText to the appropriate SubjectIdentifier instance.
+// The identifier is validated in the same way as DecodeJSON.
+func DecodeText(s string) (SubjectIdentifier, error) {
+	m, err := decodeText(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeIdentifier(m)
+}
+
+func decodeText(s string) (map[string]interface{}, error) {
+	f, body, ok := strings.Cut(s, ":")
+	if !ok || f == "" {
+		return nil, ErrNoFormat
+	}
+
+	switch Format(f) {
+	case FormatAliases:
+		inner, ok := enclosed(body, '[', ']')
+		if !ok {
+			return nil, fmt.Errorf("malformed aliases: %s", s)
+		}
+		var vs []interface{}
+		for _, part := range splitText(inner) {
+			d, err := decodeText(part)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, d)
+		}
+		return map[string]interface{}{fieldFormat: f, fieldIdentifiers: vs}, nil
+	case FormatComplex:
+		inner, ok := enclosed(body, '{', '}')
+		if !ok {
+			return nil, fmt.Errorf("malformed complex: %s", s)
+		}
+		m := make(map[string]interface{})
+		for _, part := range splitText(inner) {
+			name, member, ok := strings.Cut(part, "=")
+			if !ok {
+				return nil, fmt.Errorf("malformed complex member: %s", part)
+			}
+			d, err := decodeText(member)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = d
+		}
+		if !isComplex(m) {
+			return nil, ErrEmptyComplex
+		}
+		return m, nil
+	}
+
+	names, ok := textFields[Format(f)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format: %s", f)
+	}
+	vs := strings.Split(body, "/")
+	if len(vs) != len(names) {
+		return nil, fmt.Errorf("%s requires %d values: %s", f, len(names), s)
+	}
+	m := map[string]interface{}{fieldFormat: f}
+	for i, name := range names {
+		v, err := unescapeText(vs[i])
+		if err != nil {
+			return nil, err
+		}
+		m[name] = v
+	}
+	return m, nil
+}
+
+func enclosed(s string, open byte, close byte) (string, bool) {
+	if len(s) < 2 || s[0] != open || s[len(s)-1] != close {
+		return "", false
+	}
+	return s[1 : len(s)-1], true
+}
+
+// splitText splits the string with "," outside brackets and braces.
+func splitText(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func isTextSafe(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~@+", c) >= 0
+}
+
+func escapeText(s string) string {
+	const hex = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isTextSafe(c) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hex[c>>4])
+		sb.WriteByte(hex[c&0x0f])
+	}
+	return sb.String()
+}
+
+func unescapeText(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' {
+			if i+2 >= len(s) {
+				return "", fmt.Errorf("malformed escape: %s", s)
+			}
+			h, ok1 := unhex(s[i+1])
+			l, ok2 := unhex(s[i+2])
+			if !ok1 || !ok2 {
+				return "", fmt.Errorf("malformed escape: %s", s)
+			}
+			sb.WriteByte(h<<4 | l)
+			i += 2
+			continue
+		}
+		if !isTextSafe(c) {
+			return "", fmt.Errorf("unescaped character %q: %s", c, s)
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// Returns the text representation of the SubjectIdentifier held internally. See EncodeText for details.
+func (w *Wrapper) MarshalText() ([]byte, error) {
+	s, err := EncodeText(w.v)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (w *Wrapper) UnmarshalText(b []byte) error {
+	id, err := DecodeText(string(b))
+	if err != nil {
+		return err
+	}
+
+	w.v = id
+	return nil
+}
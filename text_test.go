@@ -0,0 +1,112 @@
+package secevsubid_test
+
+import (
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeText(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	acct, _ := secevsubid.NewAccountIdentifier("acct:user@example.com")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("a/b,c[d]%e f")
+	did, _ := secevsubid.NewDidIdentifier("did:example:123456/path?q=1#frag")
+	uri, _ := secevsubid.NewUriIdentifier("urn:uuid:4e851e98-83c4-4743-a5da-150ecb53042f")
+	jwtId, _ := secevsubid.NewJwtIdIdentifier("https://issuer.example.com/", "B70BA622")
+	saml, _ := secevsubid.NewSamlAssertionIdIdentifier("https://idp.example.com/", "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6")
+	unicode, _ := secevsubid.NewEmailIdentifier("ユーザー@例え.jp")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub, opaque)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: aliases, Device: opaque, Tenant: issSub})
+
+	tests := []struct {
+		name string
+		id   secevsubid.SubjectIdentifier
+		want string
+	}{
+		{name: "email", id: email, want: "email:user@example.com"},
+		{name: "phone_number", id: phone, want: "phone_number:+12065550100"},
+		{name: "iss_sub", id: issSub, want: "iss_sub:https%3A%2F%2Fissuer.example.com%2F/145234573"},
+		{name: "account", id: acct, want: "account:acct%3Auser@example.com"},
+		{name: "opaque", id: opaque, want: "opaque:a%2Fb%2Cc%5Bd%5D%25e%20f"},
+		{name: "did", id: did},
+		{name: "uri", id: uri},
+		{name: "jwt_id", id: jwtId, want: "jwt_id:https%3A%2F%2Fissuer.example.com%2F/B70BA622"},
+		{name: "saml_assertion_id", id: saml},
+		{name: "unicode", id: unicode},
+		{name: "aliases", id: aliases, want: "aliases:[email:user@example.com,iss_sub:https%3A%2F%2Fissuer.example.com%2F/145234573,opaque:a%2Fb%2Cc%5Bd%5D%25e%20f]"},
+		{name: "complex", id: complexSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.EncodeText(tt.id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("EncodeText() = %v, want %v", got, tt.want)
+			}
+
+			decoded, err := secevsubid.DecodeText(got)
+			if err != nil {
+				t.Errorf("DecodeText(%s) error = %v", got, err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, tt.id) {
+				t.Errorf("DecodeText() = %#v, want %#v", decoded, tt.id)
+			}
+		})
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr error
+	}{
+		{name: "no format", text: "user@example.com", wantErr: secevsubid.ErrNoFormat},
+		{name: "empty value", text: "email:", wantErr: secevsubid.ErrEmptyEmail},
+		{name: "empty aliases", text: "aliases:[]", wantErr: secevsubid.ErrEmptyIdentifiers},
+		{name: "nested aliases", text: "aliases:[aliases:[email:a@example.com]]", wantErr: secevsubid.ErrNestedAliases},
+		{name: "nested complex", text: "complex:{user=complex:{device=opaque:x}}", wantErr: secevsubid.ErrNestedComplex},
+		{name: "unknown format", text: "unknown:x"},
+		{name: "too many values", text: "email:a/b"},
+		{name: "malformed escape", text: "opaque:a%2"},
+		{name: "unescaped", text: "opaque:a b"},
+		{name: "malformed aliases", text: "aliases:email:a@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := secevsubid.DecodeText(tt.text)
+			if err == nil {
+				t.Errorf("DecodeText() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWrapper_MarshalText(t *testing.T) {
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	b, err := secevsubid.NewWrapper(issSub).MarshalText()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	w := &secevsubid.Wrapper{}
+	if err = w.UnmarshalText(b); err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(w.Value(), issSub) {
+		t.Errorf("UnmarshalText() = %v, want %v", w.Value(), issSub)
+	}
+}
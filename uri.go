@@ -1,5 +1,10 @@
 package secevsubid
 
+import (
+	"encoding/xml"
+	"log/slog"
+)
+
 // UriIdentifier is one of the sub-interfaces of SubjectIdentifier.
 // It represents the "Uniform Resource Identifier (URI) Format" defined in the specification.
 // Reference: https://datatracker.ietf.org/doc/html/draft-ietf-secevent-subject-identifiers#name-uniform-resource-identifier
@@ -34,6 +39,32 @@ func (id *uriIdentifier) Validate() error {
 	return nil
 }
 
+func (id *uriIdentifier) String() string {
+	return Mask(id, CurrentMaskingPolicy())
+}
+
+func (id *uriIdentifier) GoString() string {
+	return id.String()
+}
+
+func (id *uriIdentifier) LogValue() slog.Value {
+	return maskedLogValue(id, CurrentMaskingPolicy())
+}
+
+func (id *uriIdentifier) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalIdentifierXML(e, id, start, "uriIdentifier")
+}
+
+func (id *uriIdentifier) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := unmarshalIdentifierXML(d, start, FormatUri)
+	if err != nil {
+		return err
+	}
+
+	*id = *v.(*uriIdentifier)
+	return nil
+}
+
 // NewUriIdentifier creates new instance of UriIdentifier.,
 // The argument "uri" is required. If it's empty, this function returns error.
 func NewUriIdentifier(uri string) (UriIdentifier, error) {
@@ -0,0 +1,340 @@
+package secevsubid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Event type URIs defined in the OpenID Shared Signals Framework specification.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html
+const (
+	// EventTypeVerification is the event type URI of "Verification" event.
+	EventTypeVerification = "https://schemas.openid.net/secevent/ssf/event-type/verification"
+	// EventTypeStreamUpdated is the event type URI of "Stream Updated" event.
+	EventTypeStreamUpdated = "https://schemas.openid.net/secevent/ssf/event-type/stream-updated"
+)
+
+// VerificationEvent is the "Verification" event of SSF.
+// Its subject is the stream itself.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-verification
+type VerificationEvent struct {
+	// State is the value supplied by the receiver in the verification request.
+	State string `json:"state,omitempty"`
+}
+
+// EventType implements Event.
+func (e *VerificationEvent) EventType() string {
+	return EventTypeVerification
+}
+
+// SubjectFormats implements Event.
+func (e *VerificationEvent) SubjectFormats() []Format {
+	return []Format{FormatOpaque}
+}
+
+// Validate implements Event.
+func (e *VerificationEvent) Validate() error {
+	return nil
+}
+
+// StreamUpdatedEvent is the "Stream Updated" event of SSF.
+// Its subject is the stream itself.
+// Reference: https://openid.net/specs/openid-sharedsignals-framework-1_0.html#name-stream-updated-event
+type StreamUpdatedEvent struct {
+	// Status is the new state of the stream.
+	Status StreamState `json:"status"`
+	// Reason is the reason of the change.
+	Reason string `json:"reason,omitempty"`
+}
+
+// EventType implements Event.
+func (e *StreamUpdatedEvent) EventType() string {
+	return EventTypeStreamUpdated
+}
+
+// SubjectFormats implements Event.
+func (e *StreamUpdatedEvent) SubjectFormats() []Format {
+	return []Format{FormatOpaque}
+}
+
+// Validate implements Event.
+func (e *StreamUpdatedEvent) Validate() error {
+	switch e.Status {
+	case "":
+		return missingEventMember("status")
+	case StreamStateEnabled, StreamStatePaused, StreamStateDisabled:
+		return nil
+	}
+
+	return invalidEventMember("status", string(e.Status))
+}
+
+// NewStreamSubject creates OpaqueIdentifier representing the stream, which is the subject of stream events.
+func NewStreamSubject(streamId string) (OpaqueIdentifier, error) {
+	if streamId == "" {
+		return nil, ErrEmptyStreamId
+	}
+
+	return NewOpaqueIdentifier(streamId)
+}
+
+func newStreamEventSET(c *StreamConfiguration, e Event) (*SecurityEventToken, error) {
+	sub, err := NewStreamSubject(c.StreamId)
+	if err != nil {
+		return nil, err
+	}
+
+	jti, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &SecurityEventToken{
+		Issuer:   c.Issuer,
+		IssuedAt: time.Now().Unix(),
+		JwtId:    jti,
+		SubId:    NewWrapper(sub),
+		Audience: c.Audience,
+	}
+	if err = set.AddEvent(e); err != nil {
+		return nil, err
+	}
+	if err = set.Validate(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// NewVerificationSET creates Security Event Token of verification event for the stream.
+func NewVerificationSET(c *StreamConfiguration, state string) (*SecurityEventToken, error) {
+	return newStreamEventSET(c, &VerificationEvent{State: state})
+}
+
+// NewStreamUpdatedSET creates Security Event Token of stream updated event for the stream.
+func NewStreamUpdatedSET(c *StreamConfiguration, status *StreamStatus) (*SecurityEventToken, error) {
+	return newStreamEventSET(c, &StreamUpdatedEvent{Status: status.Status, Reason: status.Reason})
+}
+
+// DecodeStreamEvent validates that the SET holds a single stream event, either VerificationEvent or StreamUpdatedEvent,
+// and returns the stream_id of its subject with the event.
+func DecodeStreamEvent(set *SecurityEventToken) (string, Event, error) {
+	es, err := set.ValidateEvents()
+	if err != nil {
+		return "", nil, err
+	}
+	if len(es) != 1 {
+		return "", nil, ErrNotStreamEvent
+	}
+
+	switch es[0].(type) {
+	case *VerificationEvent, *StreamUpdatedEvent:
+	default:
+		return "", nil, ErrNotStreamEvent
+	}
+
+	sub, ok := set.Subject().(OpaqueIdentifier)
+	if !ok {
+		return "", nil, ErrNotStreamEvent
+	}
+
+	return sub.Id(), es[0], nil
+}
+
+// VerificationRequest is the request body of the verification endpoint.
+type VerificationRequest struct {
+	// StreamId is the identifier of the stream.
+	StreamId string `json:"stream_id"`
+	// State is an arbitrary value returned in the verification event.
+	State string `json:"state,omitempty"`
+}
+
+// VerificationHandler returns http.Handler of the verification endpoint.
+// It transmits verification event over the stream via Deliver.
+func (m *StreamManager) VerificationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, SETErrInvalidRequest, "method not allowed")
+			return
+		}
+
+		var req VerificationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, SETErrInvalidRequest, err.Error())
+			return
+		}
+
+		if err := m.Verify(r.Context(), req.StreamId, req.State); err != nil {
+			writeStreamError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// Verify transmits verification event with the state over the stream via Deliver.
+func (m *StreamManager) Verify(ctx context.Context, streamId string, state string) error {
+	if streamId == "" {
+		return ErrEmptyStreamId
+	}
+
+	c, err := m.Store.GetStream(ctx, streamId)
+	if err != nil {
+		return err
+	}
+
+	set, err := NewVerificationSET(c, state)
+	if err != nil {
+		return err
+	}
+
+	return m.deliver(ctx, c, set)
+}
+
+// UpdateStatus changes the status of the stream on the transmitter side and transmits stream updated event via Deliver.
+func (m *StreamManager) UpdateStatus(ctx context.Context, status *StreamStatus) error {
+	if err := status.Validate(); err != nil {
+		return err
+	}
+
+	c, err := m.Store.GetStream(ctx, status.StreamId)
+	if err != nil {
+		return err
+	}
+	if err = m.Store.UpdateStatus(ctx, status); err != nil {
+		return err
+	}
+
+	set, err := NewStreamUpdatedSET(c, status)
+	if err != nil {
+		return err
+	}
+
+	return m.deliver(ctx, c, set)
+}
+
+func (m *StreamManager) deliver(ctx context.Context, c *StreamConfiguration, set *SecurityEventToken) error {
+	if m.Deliver == nil {
+		return nil
+	}
+
+	return m.Deliver(ctx, c, set)
+}
+
+// RequestVerification requests the transmitter to send verification event with the state.
+func (c *StreamClient) RequestVerification(ctx context.Context, streamId string, state string) error {
+	req := &VerificationRequest{StreamId: streamId, State: state}
+	return c.do(ctx, http.MethodPost, c.Configuration.VerificationEndpoint, nil, req, nil)
+}
+
+// StreamVerifier correlates verification events with requests by "state" on the receiver side.
+// The zero value is a verifier whose states never expire.
+type StreamVerifier struct {
+	// TTL is the period a state is valid for. If zero, states never expire.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	pending map[string]pendingVerification
+}
+
+type pendingVerification struct {
+	streamId  string
+	expiresAt time.Time
+}
+
+// NewStreamVerifier creates new instance of StreamVerifier.
+func NewStreamVerifier(ttl time.Duration) *StreamVerifier {
+	return &StreamVerifier{
+		TTL:     ttl,
+		pending: make(map[string]pendingVerification),
+	}
+}
+
+// NewState generates a random state for the stream and remembers it until it is verified or expires.
+func (v *StreamVerifier) NewState(streamId string) (string, error) {
+	state, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	p := pendingVerification{streamId: streamId}
+	if v.TTL > 0 {
+		p.expiresAt = now.Add(v.TTL)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.pending == nil {
+		v.pending = make(map[string]pendingVerification)
+	}
+	v.purge(now)
+	v.pending[state] = p
+	return state, nil
+}
+
+// Len returns the number of pending states.
+func (v *StreamVerifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.pending)
+}
+
+// purge removes expired states. The caller must hold v.mu.
+func (v *StreamVerifier) purge(now time.Time) {
+	for state, p := range v.pending {
+		if !p.expiresAt.IsZero() && now.After(p.expiresAt) {
+			delete(v.pending, state)
+		}
+	}
+}
+
+// Start generates a state for the stream and requests verification via the StreamClient.
+// It returns the state to wait for.
+func (v *StreamVerifier) Start(ctx context.Context, c *StreamClient, streamId string) (string, error) {
+	state, err := v.NewState(streamId)
+	if err != nil {
+		return "", err
+	}
+
+	if err = c.RequestVerification(ctx, streamId, state); err != nil {
+		v.mu.Lock()
+		delete(v.pending, state)
+		v.mu.Unlock()
+		return "", err
+	}
+
+	return state, nil
+}
+
+// Verify validates the verification event held in the SET and correlates its state with a pending request.
+// A state is consumed by a successful verification, so replayed events result in ErrUnknownVerificationState.
+// It returns the stream_id of verified stream.
+func (v *StreamVerifier) Verify(set *SecurityEventToken) (string, error) {
+	streamId, e, err := DecodeStreamEvent(set)
+	if err != nil {
+		return "", err
+	}
+	ve, ok := e.(*VerificationEvent)
+	if !ok {
+		return "", ErrNotStreamEvent
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	p, ok := v.pending[ve.State]
+	if !ok || p.streamId != streamId {
+		return "", ErrUnknownVerificationState
+	}
+	delete(v.pending, ve.State)
+	if !p.expiresAt.IsZero() && time.Now().After(p.expiresAt) {
+		return "", ErrUnknownVerificationState
+	}
+
+	return streamId, nil
+}
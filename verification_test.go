@@ -0,0 +1,254 @@
+package secevsubid_test
+
+import (
+	"context"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type verificationEnv struct {
+	manager  *secevsubid.StreamManager
+	client   *secevsubid.StreamClient
+	key      secevsubid.HMACKey
+	received chan string
+}
+
+func newVerificationEnv(t *testing.T, method string) (*verificationEnv, func()) {
+	t.Helper()
+	env := &verificationEnv{key: secevsubid.HMACKey("secret"), received: make(chan string, 10)}
+
+	// Push delivery stand-in: receiver endpoint accepting application/secevent+jwt.
+	push := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		env.received <- string(b)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	// Poll delivery stand-in: transmitter queue served by PollHandler.
+	queue := secevsubid.NewMemoryEventQueue()
+	store := secevsubid.NewMemoryStreamStore()
+	env.manager = secevsubid.NewStreamManager(store, "https://tr.example.com", nil)
+	env.manager.Deliver = func(ctx context.Context, c *secevsubid.StreamConfiguration, set *secevsubid.SecurityEventToken) error {
+		token, err := secevsubid.EncodeSET(set, env.key)
+		if err != nil {
+			return err
+		}
+		switch c.Delivery.Method {
+		case secevsubid.DeliveryMethodPush:
+			res, err := http.Post(c.Delivery.EndpointUrl, "application/secevent+jwt", strings.NewReader(token))
+			if err != nil {
+				return err
+			}
+			return res.Body.Close()
+		default:
+			return queue.Enqueue(set.JwtId, token)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/ssf/stream", env.manager.ConfigurationHandler())
+	mux.Handle("/ssf/verify", env.manager.VerificationHandler())
+	h := secevsubid.NewPollHandler(queue)
+	h.Timeout = time.Second
+	mux.Handle("/ssf/poll", h)
+	srv := httptest.NewServer(mux)
+
+	env.client = secevsubid.NewStreamClient(&secevsubid.TransmitterConfiguration{
+		Issuer:                "https://tr.example.com",
+		ConfigurationEndpoint: srv.URL + "/ssf/stream",
+		VerificationEndpoint:  srv.URL + "/ssf/verify",
+	})
+
+	delivery := &secevsubid.StreamDelivery{Method: method, EndpointUrl: push.URL}
+	if method == secevsubid.DeliveryMethodPoll {
+		delivery.EndpointUrl = srv.URL + "/ssf/poll"
+	}
+	c, err := env.client.CreateStream(context.Background(), &secevsubid.StreamConfiguration{Audience: secevsubid.Audience{"https://receiver.example.com"}, Delivery: delivery})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if method == secevsubid.DeliveryMethodPoll {
+		go func() {
+			pc := secevsubid.NewPollClient(c.Delivery.EndpointUrl, env.key)
+			for i := 0; i < 10; i++ {
+				res, err := pc.Poll(context.Background(), &secevsubid.PollRequest{})
+				if err != nil {
+					return
+				}
+				for jti, token := range res.Sets {
+					env.received <- token
+					_, _ = pc.Poll(context.Background(), &secevsubid.PollRequest{Ack: []string{jti}, ReturnImmediately: true})
+				}
+			}
+		}()
+	}
+
+	return env, func() {
+		srv.Close()
+		push.Close()
+	}
+}
+
+func (env *verificationEnv) receive(t *testing.T) *secevsubid.SecurityEventToken {
+	t.Helper()
+	select {
+	case token := <-env.received:
+		set, err := secevsubid.DecodeSET(token, env.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return set
+	case <-time.After(3 * time.Second):
+		t.Fatal("SET was not delivered")
+	}
+	return nil
+}
+
+func TestStreamVerifier(t *testing.T) {
+	for _, method := range []string{secevsubid.DeliveryMethodPush, secevsubid.DeliveryMethodPoll} {
+		t.Run(method, func(t *testing.T) {
+			env, closeFunc := newVerificationEnv(t, method)
+			defer closeFunc()
+			ctx := context.Background()
+			streams, _ := env.client.ListStreams(ctx)
+			streamId := streams[0].StreamId
+
+			v := secevsubid.NewStreamVerifier(time.Minute)
+			if _, err := v.Start(ctx, env.client, streamId); err != nil {
+				t.Error(err)
+				return
+			}
+
+			set := env.receive(t)
+			if !set.Audience.Contains("https://receiver.example.com") {
+				t.Errorf("aud = %s, want stream audience", set.Audience)
+			}
+			got, err := v.Verify(set)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got != streamId {
+				t.Errorf("Verify() got = %s, want %s", got, streamId)
+			}
+
+			if _, err = v.Verify(set); err != secevsubid.ErrUnknownVerificationState {
+				t.Errorf("Verify() error = %v, wantErr %v", err, secevsubid.ErrUnknownVerificationState)
+			}
+		})
+	}
+}
+
+func TestStreamManager_UpdateStatus(t *testing.T) {
+	env, closeFunc := newVerificationEnv(t, secevsubid.DeliveryMethodPush)
+	defer closeFunc()
+	ctx := context.Background()
+	streams, _ := env.client.ListStreams(ctx)
+	streamId := streams[0].StreamId
+
+	err := env.manager.UpdateStatus(ctx, &secevsubid.StreamStatus{StreamId: streamId, Status: secevsubid.StreamStateDisabled, Reason: "terminated"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	set := env.receive(t)
+	gotId, e, err := secevsubid.DecodeStreamEvent(set)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	updated, ok := e.(*secevsubid.StreamUpdatedEvent)
+	if !ok || gotId != streamId || updated.Status != secevsubid.StreamStateDisabled || updated.Reason != "terminated" {
+		t.Errorf("DecodeStreamEvent() got = %s, %v, want disabled stream updated event for %s", gotId, e, streamId)
+	}
+
+	if _, err = secevsubid.NewStreamVerifier(0).Verify(set); err != secevsubid.ErrNotStreamEvent {
+		t.Errorf("Verify() error = %v, wantErr %v", err, secevsubid.ErrNotStreamEvent)
+	}
+}
+
+func TestStreamVerifierWithUnknownState(t *testing.T) {
+	c := &secevsubid.StreamConfiguration{StreamId: "stream1", Issuer: "https://tr.example.com"}
+	v := secevsubid.NewStreamVerifier(time.Minute)
+	state, _ := v.NewState("stream2")
+
+	tests := []struct {
+		name  string
+		state string
+	}{
+		{
+			name:  "not requested",
+			state: "unknown",
+		},
+		{
+			name:  "other stream",
+			state: state,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := secevsubid.NewVerificationSET(c, tt.state)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err = v.Verify(set); err != secevsubid.ErrUnknownVerificationState {
+				t.Errorf("Verify() error = %v, wantErr %v", err, secevsubid.ErrUnknownVerificationState)
+			}
+		})
+	}
+}
+
+func TestDecodeStreamEventWithOtherEvent(t *testing.T) {
+	set := newTestSET(t, "jti")
+	if _, _, err := secevsubid.DecodeStreamEvent(set); !errors.Is(err, secevsubid.ErrNotStreamEvent) {
+		t.Errorf("DecodeStreamEvent() error = %v, wantErr %v", err, secevsubid.ErrNotStreamEvent)
+	}
+}
+
+func TestStreamVerifier_ZeroValue(t *testing.T) {
+	c := &secevsubid.StreamConfiguration{StreamId: "stream1", Issuer: "https://tr.example.com"}
+	v := &secevsubid.StreamVerifier{}
+	state, err := v.NewState("stream1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	set, _ := secevsubid.NewVerificationSET(c, state)
+	got, err := v.Verify(set)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got != "stream1" {
+		t.Errorf("Verify() got = %s, want %s", got, "stream1")
+	}
+}
+
+func TestStreamVerifier_NewStatePurgesExpired(t *testing.T) {
+	v := secevsubid.NewStreamVerifier(time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := v.NewState("stream1"); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := v.NewState("stream1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if v.Len() != 1 {
+		t.Errorf("Len() = %d, want %d", v.Len(), 1)
+	}
+}
@@ -0,0 +1,310 @@
+package secevsubid
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// XMLNamespace is the namespace of the subject element generated by EncodeXML and MarshalXML.
+// Child elements have no prefix, so they inherit this namespace.
+const XMLNamespace = "https://github.com/pinzolo/secevsubid/subject"
+
+const (
+	// xmlSubjectElement is the name of the root element of EncodeXML and members of aliases.
+	xmlSubjectElement = "subject"
+	// xmlFormatAttr is the name of the attribute holding the format.
+	xmlFormatAttr = "format"
+)
+
+// EncodeXML returns XML representation of the identifier. The element schema mirrors JSON representation:
+//
+//	<subject xmlns="https://github.com/pinzolo/secevsubid/subject" format="email"><email>user@example.com</email></subject>
+//	<subject xmlns="..." format="iss_sub"><iss>https://issuer.example.com/</iss><sub>145234573</sub></subject>
+//	<subject xmlns="..." format="aliases"><identifiers><subject format="email">...</subject>...</identifiers></subject>
+//	<subject xmlns="..."><user format="email">...</user><device format="opaque">...</device></subject>
+//
+// The root element is in XMLNamespace. Each field of the format is a child element holding the value as text. Members of Aliases
+// Identifier are "subject" elements in the "identifiers" element. Complex Subject has no "format" attribute and each member is
+// the element named by the member name.
+// When Wrapper or identifiers are embedded in other XML documents, the name of the root element is the one given by the document,
+// and the namespace is XMLNamespace unless the document gives one.
+func EncodeXML(id SubjectIdentifier) ([]byte, error) {
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+	if err := encodeXML(e, id, xml.StartElement{Name: xml.Name{Space: XMLNamespace, Local: xmlSubjectElement}}); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeXML(e *xml.Encoder, id SubjectIdentifier, start xml.StartElement) error {
+	m, err := identifierMap(id)
+	if err != nil {
+		return err
+	}
+
+	return encodeXMLMap(e, m, start)
+}
+
+func encodeXMLMap(e *xml.Encoder, m map[string]interface{}, start xml.StartElement) error {
+	start.Attr = nil
+	f, ok := m[fieldFormat].(string)
+	if ok {
+		start.Attr = []xml.Attr{{Name: xml.Name{Local: xmlFormatAttr}, Value: f}}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch {
+	case !ok:
+		for _, name := range complexMemberFields {
+			d, ok := m[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := encodeXMLMap(e, d, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+	case Format(f) == FormatAliases:
+		ids := xml.StartElement{Name: xml.Name{Local: fieldIdentifiers}}
+		if err := e.EncodeToken(ids); err != nil {
+			return err
+		}
+		vs, _ := m[fieldIdentifiers].([]interface{})
+		for _, v := range vs {
+			d, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("not JSON object: %v", v)
+			}
+			if err := encodeXMLMap(e, d, xml.StartElement{Name: xml.Name{Local: xmlSubjectElement}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(ids.End()); err != nil {
+			return err
+		}
+	default:
+		names, ok := textFields[Format(f)]
+		if !ok {
+			return fmt.Errorf("unknown format: %s", f)
+		}
+		for _, name := range names {
+			v := extractStringValue(m, name)
+			if !isXMLText(v) {
+				return fmt.Errorf("%s contains characters not allowed in XML", name)
+			}
+			if err := e.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// isXMLText returns whether all characters are allowed in XML 1.0, so that values round-trip.
+func isXMLText(s string) bool {
+	for _, r := range s {
+		switch {
+		case r == 0x09 || r == 0x0A || r == 0x0D:
+		case r >= 0x20 && r <= 0xD7FF:
+		case r >= 0xE000 && r <= 0xFFFD:
+		case r >= 0x10000 && r <= 0x10FFFF:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// DecodeXML decodes XML representation generated by EncodeXML to the appropriate SubjectIdentifier instance.
+// The local name of the root element is not checked, and the identifier is validated in the same way as DecodeJSON.
+// Elements must be in XMLNamespace or have no namespace.
+func DecodeXML(b []byte) (SubjectIdentifier, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			if start.Name.Space != "" && start.Name.Space != XMLNamespace {
+				return nil, fmt.Errorf("unexpected namespace: %s", start.Name.Space)
+			}
+			return decodeXML(d, start)
+		}
+	}
+}
+
+func decodeXML(d *xml.Decoder, start xml.StartElement) (SubjectIdentifier, error) {
+	m, err := decodeXMLMap(d, start)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeIdentifier(m)
+}
+
+func decodeXMLMap(d *xml.Decoder, start xml.StartElement) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for _, a := range start.Attr {
+		if a.Name.Space == "" && a.Name.Local == xmlFormatAttr {
+			m[fieldFormat] = a.Value
+		}
+	}
+	f, hasFormat := m[fieldFormat].(string)
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			if err = checkXMLNamespace(t, start); err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			if _, ok := m[name]; ok || name == fieldFormat {
+				return nil, fmt.Errorf("duplicated element: %s", name)
+			}
+
+			switch {
+			case !hasFormat || Format(f) == FormatComplex:
+				if !containsString(complexMemberFields, name) {
+					return nil, ErrNoFormat
+				}
+				if m[name], err = decodeXMLMap(d, t); err != nil {
+					return nil, err
+				}
+			case Format(f) == FormatAliases && name == fieldIdentifiers:
+				if m[name], err = decodeXMLList(d, t); err != nil {
+					return nil, err
+				}
+			default:
+				var s string
+				if err = d.DecodeElement(&s, &t); err != nil {
+					return nil, err
+				}
+				m[name] = s
+			}
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return nil, fmt.Errorf("unexpected text in %s", start.Name.Local)
+			}
+		case xml.EndElement:
+			return m, nil
+		}
+	}
+}
+
+func decodeXMLList(d *xml.Decoder, start xml.StartElement) ([]interface{}, error) {
+	var vs []interface{}
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			if err = checkXMLNamespace(t, start); err != nil {
+				return nil, err
+			}
+			v, err := decodeXMLMap(d, t)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return nil, fmt.Errorf("unexpected text in %s", fieldIdentifiers)
+			}
+		case xml.EndElement:
+			if vs == nil {
+				vs = []interface{}{}
+			}
+			return vs, nil
+		}
+	}
+}
+
+// checkXMLNamespace returns an error if the child element is in other namespace than XMLNamespace or the one of its parent.
+func checkXMLNamespace(child xml.StartElement, parent xml.StartElement) error {
+	switch child.Name.Space {
+	case "", XMLNamespace, parent.Name.Space:
+		return nil
+	}
+
+	return fmt.Errorf("unexpected namespace: %s", child.Name.Space)
+}
+
+// subjectStartElement returns the root element for the identifier.
+// If the element name is the type name, i.e. not given by the document, "subject" is used.
+// If the document gives no namespace, XMLNamespace is used.
+func subjectStartElement(start xml.StartElement, typeName string) xml.StartElement {
+	if start.Name.Local == typeName {
+		start.Name.Local = xmlSubjectElement
+	}
+	if start.Name.Space == "" {
+		start.Name.Space = XMLNamespace
+	}
+
+	return start
+}
+
+// marshalIdentifierXML is the implementation of xml.Marshaler shared by identifier types.
+func marshalIdentifierXML(e *xml.Encoder, id SubjectIdentifier, start xml.StartElement, typeName string) error {
+	return encodeXML(e, id, subjectStartElement(start, typeName))
+}
+
+// unmarshalIdentifierXML is the implementation of xml.Unmarshaler shared by identifier types.
+// It returns an error if the format of the element is not the expected one.
+func unmarshalIdentifierXML(d *xml.Decoder, start xml.StartElement, f Format) (SubjectIdentifier, error) {
+	id, err := decodeXML(d, start)
+	if err != nil {
+		return nil, err
+	}
+	if id.Format() != f {
+		return nil, fmt.Errorf("format must be %s: %s", f, id.Format())
+	}
+
+	return id, nil
+}
+
+// MarshalXML implements xml.Marshaler. See EncodeXML for the element schema.
+// If the element name is not given by the document, "subject" is used.
+func (w *Wrapper) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeXML(e, w.v, subjectStartElement(start, "Wrapper"))
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (w *Wrapper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	id, err := decodeXML(d, start)
+	if err != nil {
+		return err
+	}
+
+	w.v = id
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,190 @@
+package secevsubid_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/pinzolo/secevsubid"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeXML(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	opaque, _ := secevsubid.NewOpaqueIdentifier("<a & b>\r\n\"c\"")
+	saml, _ := secevsubid.NewSamlAssertionIdIdentifier("https://idp.example.com/", "_8e8dc5f69a98cc4c1ff3427e5ce34606fd672f91e6")
+	aliases, _ := secevsubid.NewAliasesIdentifier(email, issSub)
+	complexSubject, _ := secevsubid.NewComplexIdentifier(secevsubid.ComplexMembers{User: aliases, Device: opaque})
+
+	tests := []struct {
+		name string
+		id   secevsubid.SubjectIdentifier
+		want string
+	}{
+		{name: "email", id: email, want: `<subject xmlns="https://github.com/pinzolo/secevsubid/subject" format="email"><email>user@example.com</email></subject>`},
+		{name: "iss_sub", id: issSub, want: `<subject xmlns="https://github.com/pinzolo/secevsubid/subject" format="iss_sub"><iss>https://issuer.example.com/</iss><sub>145234573</sub></subject>`},
+		{name: "escaped", id: opaque},
+		{name: "saml", id: saml},
+		{
+			name: "aliases",
+			id:   aliases,
+			want: `<subject xmlns="https://github.com/pinzolo/secevsubid/subject" format="aliases"><identifiers>` +
+				`<subject format="email"><email>user@example.com</email></subject>` +
+				`<subject format="iss_sub"><iss>https://issuer.example.com/</iss><sub>145234573</sub></subject>` +
+				`</identifiers></subject>`,
+		},
+		{name: "complex", id: complexSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secevsubid.EncodeXML(tt.id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tt.want != "" && string(got) != tt.want {
+				t.Errorf("EncodeXML() = %s, want %s", got, tt.want)
+			}
+
+			decoded, err := secevsubid.DecodeXML(got)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, tt.id) {
+				t.Errorf("DecodeXML(%s) = %v, want %v", got, decoded, tt.id)
+			}
+
+			// XML and JSON representations are converted to each other losslessly.
+			jb, _ := json.Marshal(tt.id)
+			fromJSON, _ := secevsubid.DecodeJSON(jb)
+			if xb, _ := secevsubid.EncodeXML(fromJSON); string(xb) != string(got) {
+				t.Errorf("EncodeXML() via JSON = %s, want %s", xb, got)
+			}
+		})
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		wantErr error
+	}{
+		{name: "no format", xml: `<subject><email>user@example.com</email></subject>`, wantErr: secevsubid.ErrNoFormat},
+		{name: "empty value", xml: `<subject format="email"></subject>`, wantErr: secevsubid.ErrEmptyEmail},
+		{name: "empty aliases", xml: `<subject format="aliases"><identifiers></identifiers></subject>`, wantErr: secevsubid.ErrEmptyIdentifiers},
+		{name: "nested aliases", xml: `<subject format="aliases"><identifiers><subject format="aliases"/></identifiers></subject>`, wantErr: secevsubid.ErrNestedAliases},
+		{name: "nested complex", xml: `<subject><user><device format="opaque"><id>x</id></device></user></subject>`, wantErr: secevsubid.ErrNestedComplex},
+		{name: "duplicated element", xml: `<subject format="email"><email>a@example.com</email><email>b@example.com</email></subject>`},
+		{name: "unexpected text", xml: `<subject format="email">user@example.com</subject>`},
+		{name: "unclosed", xml: `<subject format="email"><email>user@example.com</email>`},
+		{name: "unknown format", xml: `<subject format="unknown"/>`},
+		{name: "unknown namespace", xml: `<subject xmlns="urn:example" format="email"><email>user@example.com</email></subject>`},
+		{name: "unknown namespace of child", xml: `<subject format="email"><email xmlns="urn:example">user@example.com</email></subject>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := secevsubid.DecodeXML([]byte(tt.xml))
+			if err == nil {
+				t.Errorf("DecodeXML() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeXML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	email, _ := secevsubid.NewEmailIdentifier("invalid\x00@example.com")
+	if _, err := secevsubid.EncodeXML(email); err == nil {
+		t.Errorf("EncodeXML() error = nil, want error for characters not allowed in XML")
+	}
+}
+
+func TestWrapper_MarshalXML(t *testing.T) {
+	type extension struct {
+		XMLName xml.Name            `xml:"Extensions"`
+		Subject *secevsubid.Wrapper `xml:"SubId"`
+	}
+
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	b, err := xml.Marshal(extension{Subject: secevsubid.NewWrapper(email)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := `<Extensions><SubId xmlns="https://github.com/pinzolo/secevsubid/subject" format="email"><email>user@example.com</email></SubId></Extensions>`
+	if string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+
+	var got extension
+	if err = xml.Unmarshal(b, &got); err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got.Subject.Value(), email) {
+		t.Errorf("Unmarshal() = %v, want %v", got.Subject.Value(), email)
+	}
+
+	if b, _ = xml.Marshal(secevsubid.NewWrapper(email)); string(b) != `<subject xmlns="https://github.com/pinzolo/secevsubid/subject" format="email"><email>user@example.com</email></subject>` {
+		t.Errorf("Marshal() = %s, want subject element", b)
+	}
+}
+
+func TestDecodeXMLWithNamespace(t *testing.T) {
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	for _, x := range []string{
+		`<subject format="email"><email>user@example.com</email></subject>`,
+		`<s:subject xmlns:s="https://github.com/pinzolo/secevsubid/subject" format="email"><s:email>user@example.com</s:email></s:subject>`,
+	} {
+		got, err := secevsubid.DecodeXML([]byte(x))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(got, email) {
+			t.Errorf("DecodeXML(%s) = %v, want %v", x, got, email)
+		}
+	}
+}
+
+func TestIdentifier_MarshalXML(t *testing.T) {
+	type extension struct {
+		XMLName xml.Name                     `xml:"Extensions"`
+		Subject secevsubid.SubjectIdentifier `xml:"SubId"`
+	}
+
+	issSub, _ := secevsubid.NewIssuerSubjectIdentifier("https://issuer.example.com/", "145234573")
+	b, err := xml.Marshal(extension{Subject: issSub})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := `<Extensions><SubId xmlns="https://github.com/pinzolo/secevsubid/subject" format="iss_sub">` +
+		`<iss>https://issuer.example.com/</iss><sub>145234573</sub></SubId></Extensions>`
+	if string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+
+	email, _ := secevsubid.NewEmailIdentifier("user@example.com")
+	if b, _ = xml.Marshal(email); string(b) != `<subject xmlns="https://github.com/pinzolo/secevsubid/subject" format="email"><email>user@example.com</email></subject>` {
+		t.Errorf("Marshal() = %s, want subject element", b)
+	}
+
+	got, _ := secevsubid.NewEmailIdentifier("other@example.com")
+	if err = xml.Unmarshal(b, got); err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(got, email) {
+		t.Errorf("Unmarshal() = %v, want %v", got, email)
+	}
+
+	phone, _ := secevsubid.NewPhoneNumberIdentifier("+12065550100")
+	if err = xml.Unmarshal(b, phone); err == nil {
+		t.Errorf("Unmarshal() error = nil, want error for other format")
+	}
+}